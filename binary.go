@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"path"
+
+	"github.com/piwriw/go-logger/binlog"
+)
+
+// appendRecordBinary 编码为 binlog 二进制格式，包含长度前缀，不以换行结尾
+// 调用文件只保留文件名，与 Caller 一致
+func appendRecordBinary(dst []byte, r *Record) []byte {
+	e := binlog.Entry{
+		Time:    r.Time,
+		Level:   int(r.Level),
+		Message: r.Message,
+		Logger:  r.Logger,
+		Line:    r.Line,
+		Fields:  make([]binlog.Field, len(r.Fields)),
+	}
+	if r.File != "" {
+		e.File = path.Base(r.File)
+	}
+	for i, f := range r.Fields {
+		e.Fields[i] = binlog.Field{Key: f.Key, Value: f.Value}
+	}
+	return binlog.AppendEntry(dst, &e)
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/piwriw/go-logger/binlog"
+)
+
+func TestBinaryFormat(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithFormat(FormatBinary),
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithName("ingest"),
+				WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("batch stored", "count", 128, "ok", true, "err", errors.New("partial"))
+			logger.Warn("slow batch", "tags", []string{"a", "b"})
+			logger.Debug("hidden")
+
+			r := binlog.NewReader(strings.NewReader(out.String()))
+			e, err := r.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.LevelString() != "INFO" || e.Message != "batch stored" || e.Logger != "ingest" || len(e.Fields) != 3 {
+				t.Fatalf("entry = %+v", e)
+			}
+			if e.Fields[0].Value != int64(128) || e.Fields[1].Value != true || e.Fields[2].Value != binlog.Error("partial") {
+				t.Fatalf("fields = %+v", e.Fields)
+			}
+			if e, err = r.Next(); err != nil || e.Level != int(WarnLevel) {
+				t.Fatalf("entry = %+v, err = %v", e, err)
+			}
+			if _, err = r.Next(); err != io.EOF {
+				t.Fatalf("err = %v, want EOF", err)
+			}
+
+			var converted bytes.Buffer
+			n, err := binlog.Convert(&converted, strings.NewReader(out.String()), binlog.ConvertOptions{})
+			if err != nil || n != 2 {
+				t.Fatalf("n = %d, err = %v", n, err)
+			}
+			if !strings.Contains(converted.String(), `"logger":"ingest","error":"partial","count":128`) ||
+				!strings.Contains(converted.String(), `"msg":"slow batch","logger":"ingest","tags":["a","b"]}`) {
+				t.Fatalf("converted = %q", converted.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+// Package binlog 紧凑的二进制日志格式
+//
+// 每条日志由 uvarint 长度前缀和内容组成，内容不依赖前面的日志，文件可以追加写入、轮转和截断。
+// 内容依次为：版本号、时间（Unix 纳秒，zigzag varint）、级别、日志内容、日志名称、调用文件和行号、字段，
+// 字符串以 uvarint 长度前缀编码，字段值带类型标记，整数使用 varint，复合类型编码为 JSON。
+//
+// go-logger 通过 WithFormat(logger.FormatBinary) 写入该格式，Reader 和 Convert 将其还原为 JSON 或文本。
+package binlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Version 当前的格式版本
+const Version = 1
+
+// MaxEntrySize 单条日志的最大字节数，超过时视为数据损坏
+const MaxEntrySize = 64 << 20
+
+// 字段值的类型标记
+const (
+	tagNil byte = iota
+	tagString
+	tagInt
+	tagUint
+	tagFloat
+	tagTrue
+	tagFalse
+	tagBytes
+	tagTime
+	tagDuration
+	tagError
+	tagJSON
+)
+
+var (
+	// ErrCorrupt 日志内容无法解析
+	ErrCorrupt = errors.New("binlog: corrupt entry")
+	// ErrVersion 不支持的格式版本
+	ErrVersion = errors.New("binlog: unsupported version")
+)
+
+// levelNames 级别名称，与 logger.Level 的取值一致
+var levelNames = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// Field 日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Entry 一条日志
+// 解码后字段值的类型为 string、int64、uint64、float64、bool、[]byte、time.Time、time.Duration、
+// nil、Error 或 json.RawMessage（编码前为复合类型）
+type Entry struct {
+	Time    time.Time
+	Level   int // 与 logger.Level 一致，DebugLevel 为 0
+	Message string
+	Logger  string
+	File    string
+	Line    int
+	Fields  []Field
+}
+
+// Error 编码前为 error 的字段值
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// LevelString 返回级别的大写名称
+func (e *Entry) LevelString() string {
+	if e.Level >= 0 && e.Level < len(levelNames) {
+		return levelNames[e.Level]
+	}
+	return fmt.Sprintf("LEVEL(%d)", e.Level)
+}
+
+// Caller 返回 file:line，没有调用信息时返回空字符串
+func (e *Entry) Caller() string {
+	if e.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", e.File, e.Line)
+}
+
+// AppendEntry 将日志编码后追加到 dst，包含长度前缀
+func AppendEntry(dst []byte, e *Entry) []byte {
+	body := make([]byte, 0, 64+len(e.Message)+16*len(e.Fields))
+	body = append(body, Version)
+	body = binary.AppendVarint(body, e.Time.UnixNano())
+	body = binary.AppendVarint(body, int64(e.Level))
+	body = appendString(body, e.Message)
+	body = appendString(body, e.Logger)
+	body = appendString(body, e.File)
+	body = binary.AppendUvarint(body, uint64(e.Line))
+	body = binary.AppendUvarint(body, uint64(len(e.Fields)))
+	for _, f := range e.Fields {
+		body = appendString(body, f.Key)
+		body = appendValue(body, f.Value)
+	}
+	dst = binary.AppendUvarint(dst, uint64(len(body)))
+	return append(dst, body...)
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendValue(dst []byte, v any) []byte {
+	switch val := v.(type) {
+	case nil:
+		return append(dst, tagNil)
+	case string:
+		return appendString(append(dst, tagString), val)
+	case bool:
+		if val {
+			return append(dst, tagTrue)
+		}
+		return append(dst, tagFalse)
+	case int:
+		return binary.AppendVarint(append(dst, tagInt), int64(val))
+	case int8:
+		return binary.AppendVarint(append(dst, tagInt), int64(val))
+	case int16:
+		return binary.AppendVarint(append(dst, tagInt), int64(val))
+	case int32:
+		return binary.AppendVarint(append(dst, tagInt), int64(val))
+	case int64:
+		return binary.AppendVarint(append(dst, tagInt), val)
+	case uint:
+		return binary.AppendUvarint(append(dst, tagUint), uint64(val))
+	case uint8:
+		return binary.AppendUvarint(append(dst, tagUint), uint64(val))
+	case uint16:
+		return binary.AppendUvarint(append(dst, tagUint), uint64(val))
+	case uint32:
+		return binary.AppendUvarint(append(dst, tagUint), uint64(val))
+	case uint64:
+		return binary.AppendUvarint(append(dst, tagUint), val)
+	case float32:
+		return binary.LittleEndian.AppendUint64(append(dst, tagFloat), math.Float64bits(float64(val)))
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(dst, tagFloat), math.Float64bits(val))
+	case []byte:
+		dst = binary.AppendUvarint(append(dst, tagBytes), uint64(len(val)))
+		return append(dst, val...)
+	case time.Time:
+		return binary.AppendVarint(append(dst, tagTime), val.UnixNano())
+	case time.Duration:
+		return binary.AppendVarint(append(dst, tagDuration), int64(val))
+	case json.Marshaler:
+		return appendJSON(dst, val)
+	case error:
+		return appendString(append(dst, tagError), val.Error())
+	case fmt.Stringer:
+		return appendString(append(dst, tagString), val.String())
+	default:
+		return appendJSON(dst, val)
+	}
+}
+
+// appendJSON 复合类型编码为 JSON，无法编码时使用 fmt.Sprint 文本
+func appendJSON(dst []byte, v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return appendString(append(dst, tagString), fmt.Sprint(v))
+	}
+	dst = binary.AppendUvarint(append(dst, tagJSON), uint64(len(data)))
+	return append(dst, data...)
+}
+
+// Reader 按顺序读取二进制日志
+type Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewReader 创建 Reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next 读取下一条日志，没有更多日志时返回 io.EOF，最后一条日志不完整时返回 io.ErrUnexpectedEOF
+func (r *Reader) Next() (*Entry, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, ErrCorrupt
+	}
+	if size > MaxEntrySize {
+		return nil, ErrCorrupt
+	}
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	body := r.buf[:size]
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodeEntry(body)
+}
+
+// decodeEntry 解码一条不含长度前缀的日志内容
+func decodeEntry(body []byte) (*Entry, error) {
+	d := decoder{data: body}
+	if version := d.byte(); version != Version {
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, ErrVersion
+	}
+	e := &Entry{
+		Time:    time.Unix(0, d.varint()),
+		Level:   int(d.varint()),
+		Message: d.string(),
+		Logger:  d.string(),
+		File:    d.string(),
+		Line:    int(d.uvarint()),
+	}
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		return nil, ErrCorrupt
+	}
+	if n > 0 {
+		e.Fields = make([]Field, 0, n)
+	}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := d.string()
+		e.Fields = append(e.Fields, Field{Key: key, Value: d.value()})
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return e, nil
+}
+
+// decoder 读取日志内容，出错后后续读取返回零值，由调用方统一检查 err
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail() {
+	d.err = ErrCorrupt
+	d.data = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail()
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes())
+}
+
+func (d *decoder) value() any {
+	switch tag := d.byte(); tag {
+	case tagNil:
+		return nil
+	case tagString:
+		return d.string()
+	case tagError:
+		return Error(d.string())
+	case tagInt:
+		return d.varint()
+	case tagUint:
+		return d.uvarint()
+	case tagFloat:
+		if len(d.data) < 8 {
+			d.fail()
+			return nil
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return v
+	case tagTrue:
+		return true
+	case tagFalse:
+		return false
+	case tagBytes:
+		return append([]byte(nil), d.bytes()...)
+	case tagTime:
+		return time.Unix(0, d.varint())
+	case tagDuration:
+		return time.Duration(d.varint())
+	case tagJSON:
+		return json.RawMessage(append([]byte(nil), d.bytes()...))
+	default:
+		d.fail()
+		return nil
+	}
+}
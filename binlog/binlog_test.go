@@ -0,0 +1,89 @@
+package binlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 30, 0, 123, time.UTC)
+	in := &Entry{
+		Time:    now,
+		Level:   3,
+		Message: "query failed\nretrying",
+		Logger:  "db",
+		File:    "store.go",
+		Line:    42,
+		Fields: []Field{
+			{Key: "nil", Value: nil},
+			{Key: "str", Value: "v"},
+			{Key: "int", Value: -7},
+			{Key: "uint", Value: uint64(1 << 63)},
+			{Key: "float", Value: 1.5},
+			{Key: "bool", Value: false},
+			{Key: "bytes", Value: []byte{0, 1}},
+			{Key: "time", Value: now},
+			{Key: "elapsed", Value: 3 * time.Second},
+			{Key: "err", Value: errors.New("timeout")},
+			{Key: "map", Value: map[string]int{"a": 1}},
+		},
+	}
+	var data []byte
+	data = AppendEntry(data, in)
+	data = AppendEntry(data, &Entry{Time: now, Message: "second"})
+
+	r := NewReader(bytes.NewReader(data))
+	out, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Time.Equal(now) || out.LevelString() != "ERROR" || out.Message != in.Message || out.Caller() != "store.go:42" {
+		t.Fatalf("entry = %+v", out)
+	}
+	want := []any{nil, "v", int64(-7), uint64(1 << 63), 1.5, false, []byte{0, 1}, now, 3 * time.Second, "timeout", json.RawMessage(`{"a":1}`)}
+	for i, f := range out.Fields {
+		got, _ := json.Marshal(f.Value)
+		exp, _ := json.Marshal(want[i])
+		if f.Key != in.Fields[i].Key || !bytes.Equal(got, exp) {
+			t.Fatalf("field %d = %#v, want %#v", i, f, want[i])
+		}
+	}
+	if out, err = r.Next(); err != nil || out.Message != "second" || out.Fields != nil {
+		t.Fatalf("entry = %+v, err = %v", out, err)
+	}
+	if _, err = r.Next(); err != io.EOF {
+		t.Fatalf("err = %v, want EOF", err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	data := AppendEntry(nil, &Entry{Time: time.Now(), Message: "complete"})
+	data = append(data, AppendEntry(nil, &Entry{Time: time.Now(), Message: "partial"})[:5]...)
+
+	var out bytes.Buffer
+	n, err := Convert(&out, bytes.NewReader(data), ConvertOptions{Format: FormatText, Location: time.UTC})
+	if n != 1 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if !strings.Contains(out.String(), "level=DEBUG msg=complete") {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	data := AppendEntry(nil, &Entry{Time: time.Now(), Message: "x", Fields: []Field{{Key: "k", Value: "v"}}})
+	data[len(data)-2] = 0xff // 字段值的长度
+	if _, err := NewReader(bytes.NewReader(data)).Next(); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("err = %v, want ErrCorrupt", err)
+	}
+	data = AppendEntry(nil, &Entry{Time: time.Now()})
+	data[1] = Version + 1
+	if _, err := NewReader(bytes.NewReader(data)).Next(); !errors.Is(err, ErrVersion) {
+		t.Fatalf("err = %v, want ErrVersion", err)
+	}
+}
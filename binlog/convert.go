@@ -0,0 +1,170 @@
+package binlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/piwriw/go-logger/internal/textenc"
+)
+
+// Format 转换后的格式
+type Format int
+
+const (
+	FormatJSON Format = iota // 每行一个 JSON 对象，与 go-logger 的 JSON 格式一致
+	FormatText               // key=value 文本，与 go-logger 的文本格式一致
+)
+
+// ConvertOptions 转换配置
+type ConvertOptions struct {
+	// Format 转换后的格式，默认 JSON
+	Format Format
+	// TimeFormat 时间格式，默认 2006-01-02 15:04:05
+	TimeFormat string
+	// Location 时区，默认本地时区
+	Location *time.Location
+}
+
+// Convert 将二进制日志转换为 JSON 或文本写入 dst，返回转换的条数
+// 最后一条日志不完整时（例如进程退出时写了一半）忽略该条并返回 io.ErrUnexpectedEOF
+func Convert(dst io.Writer, src io.Reader, opts ConvertOptions) (int, error) {
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = time.DateTime
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	w := bufio.NewWriter(dst)
+	r := NewReader(src)
+	var buf bytes.Buffer
+	n := 0
+	for {
+		e, err := r.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if flushErr := w.Flush(); err == nil {
+				err = flushErr
+			}
+			return n, err
+		}
+		buf.Reset()
+		if opts.Format == FormatText {
+			AppendText(&buf, e, opts.TimeFormat, opts.Location)
+		} else {
+			AppendJSON(&buf, e, opts.TimeFormat, opts.Location)
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// AppendJSON 将日志编码为 JSON 对象，key 依次为 time、level、msg、logger、caller、error 和字段，与 logger.DefaultSchema 一致
+// 第一个 Error 类型的字段写入 error
+func AppendJSON(buf *bytes.Buffer, e *Entry, timeFormat string, location *time.Location) {
+	buf.WriteString(`{"time":`)
+	writeJSON(buf, e.Time.In(location).Format(timeFormat))
+	buf.WriteString(`,"level":`)
+	writeJSON(buf, e.LevelString())
+	buf.WriteString(`,"msg":`)
+	writeJSON(buf, e.Message)
+	if e.Logger != "" {
+		buf.WriteString(`,"logger":`)
+		writeJSON(buf, e.Logger)
+	}
+	if caller := e.Caller(); caller != "" {
+		buf.WriteString(`,"caller":`)
+		writeJSON(buf, caller)
+	}
+	fields := e.Fields
+	if i := errorField(fields); i >= 0 {
+		buf.WriteString(`,"error":`)
+		writeJSON(buf, fields[i].Value)
+		fields = append(fields[:i:i], fields[i+1:]...)
+	}
+	for _, f := range fields {
+		buf.WriteByte(',')
+		writeJSON(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSON(buf, f.Value)
+	}
+	buf.WriteByte('}')
+}
+
+func writeJSON(buf *bytes.Buffer, v any) {
+	if raw, ok := v.(json.RawMessage); ok {
+		buf.Write(raw)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+// AppendText 将日志编码为 key=value 文本，key 与 AppendJSON 一致
+func AppendText(buf *bytes.Buffer, e *Entry, timeFormat string, location *time.Location) {
+	writeTextPair(buf, "time", e.Time.In(location).Format(timeFormat))
+	buf.WriteByte(' ')
+	writeTextPair(buf, "level", e.LevelString())
+	buf.WriteByte(' ')
+	writeTextPair(buf, "msg", e.Message)
+	if e.Logger != "" {
+		buf.WriteByte(' ')
+		writeTextPair(buf, "logger", e.Logger)
+	}
+	if caller := e.Caller(); caller != "" {
+		buf.WriteByte(' ')
+		writeTextPair(buf, "caller", caller)
+	}
+	fields := e.Fields
+	if i := errorField(fields); i >= 0 {
+		buf.WriteByte(' ')
+		writeTextPair(buf, "error", fields[i].Value)
+		fields = append(fields[:i:i], fields[i+1:]...)
+	}
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		writeTextPair(buf, f.Key, f.Value)
+	}
+}
+
+// errorField 第一个 Error 类型字段的下标，没有时返回 -1
+func errorField(fields []Field) int {
+	for i, f := range fields {
+		if _, ok := f.Value.(Error); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeTextPair(buf *bytes.Buffer, key string, value any) {
+	textenc.WriteString(buf, key)
+	buf.WriteByte('=')
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("<nil>")
+	case string:
+		textenc.WriteString(buf, v)
+	case json.RawMessage:
+		textenc.WriteString(buf, string(v))
+	case []byte:
+		textenc.WriteString(buf, base64.StdEncoding.EncodeToString(v))
+	case time.Time:
+		textenc.WriteString(buf, v.Format(time.RFC3339Nano))
+	default:
+		textenc.WriteString(buf, fmt.Sprint(v))
+	}
+}
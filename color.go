@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"strings"
+
 	"github.com/fatih/color"
 )
 
@@ -14,6 +16,7 @@ var (
 	ColorPurple = Color{"\033[35m", color.New(color.FgMagenta)}
 	ColorCyan   = Color{"\033[36m", color.New(color.FgCyan)}
 	ColorWhite  = Color{"\033[37m", color.New(color.FgWhite)}
+	ColorGray   = Color{"\033[90m", color.New(color.FgHiBlack)}
 )
 
 // 默认主题配置
@@ -26,6 +29,9 @@ var (
 		Warn:     &ColorYellow,
 		Error:    &ColorRed,
 		Fatal:    &ColorPurple,
+		Key:      &ColorCyan,
+		Value:    &ColorBlue,
+		Meta:     &ColorGray,
 	}
 
 	// DefaultFatihColorScheme Fatih 默认主题
@@ -36,6 +42,9 @@ var (
 		Warn:     &ColorYellow,
 		Error:    &ColorRed,
 		Fatal:    &ColorPurple,
+		Key:      &ColorCyan,
+		Value:    &ColorBlue,
+		Meta:     &ColorGray,
 	}
 
 	// HighContrastColorScheme 高对比度主题
@@ -46,6 +55,9 @@ var (
 		Warn:     &Color{"\033[33;1m", color.New(color.FgYellow, color.Bold)},
 		Error:    &Color{"\033[31;1;4m", color.New(color.FgRed, color.Bold, color.Underline)},
 		Fatal:    &Color{"\033[35;1;7m", color.New(color.FgMagenta, color.Bold, color.BgWhite)},
+		Key:      &Color{"\033[36;1m", color.New(color.FgCyan, color.Bold)},
+		Value:    &Color{"\033[34;1m", color.New(color.FgBlue, color.Bold)},
+		Meta:     &ColorWhite,
 	}
 )
 
@@ -67,6 +79,11 @@ type ColorScheme struct {
 	Warn     *Color
 	Error    *Color
 	Fatal    *Color
+	// Key 字段名的颜色，Value 字段值的颜色，Meta 时间和调用信息的颜色，只用于 FormatPretty，为空时不着色
+	// error 类型的字段值使用 Error 的颜色
+	Key   *Color
+	Value *Color
+	Meta  *Color
 }
 
 // Colorize 使用 fatih/color 的实现
@@ -86,8 +103,25 @@ func (col *ColorScheme) Colorize(level Level, msg string) string {
 	default:
 		return msg
 	}
+	return col.sprint(c, msg)
+}
+
+// sprintLines 多行文本逐行着色，避免颜色控制字符跨行
+func (col *ColorScheme) sprintLines(c *Color, s string) string {
+	if col == nil || c == nil {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = col.sprint(c, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sprint 按 CodeType 为文本着色，颜色为空时返回原文本
+func (col *ColorScheme) sprint(c *Color, msg string) string {
 	// 检查颜色指针是否为nil
-	if c == nil {
+	if col == nil || c == nil {
 		return msg
 	}
 	switch col.CodeType {
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	ecsVersion          = "1.6.0"
+	defaultECSNamespace = "labels"
+)
+
+// WithECSNamespace sets the namespace of user fields in ECS output
+// WithECSNamespace 设置 ECS 格式中用户字段所在的对象，默认 labels
+// 为空时用户字段直接写在顶层，需要自行避免与 ECS 字段冲突
+func WithECSNamespace(namespace string) Option {
+	return func(o *Options) {
+		o.ecsNamespace = namespace
+	}
+}
+
+// appendRecordECS 编码为 Elastic Common Schema JSON
+// 时间写入 @timestamp（RFC 3339，忽略 TimeFormat），级别写入 log.level，调用信息写入 log.origin，
+// 第一个 error 类型的字段写入 error.message/error.type/error.stack_trace，其余字段写入 namespace
+func appendRecordECS(buf *bytes.Buffer, r *Record, location *time.Location, namespace string) {
+	buf.WriteString(`{"@timestamp":`)
+	writeJSONValue(buf, r.Time.In(location).Format(time.RFC3339Nano))
+	buf.WriteString(`,"log.level":`)
+	writeJSONValue(buf, strings.ToLower(r.Level.String()))
+	buf.WriteString(`,"message":`)
+	writeJSONValue(buf, r.Message)
+	buf.WriteString(`,"ecs.version":"` + ecsVersion + `"`)
+	if r.Logger != "" {
+		buf.WriteString(`,"log.logger":`)
+		writeJSONValue(buf, r.Logger)
+	}
+	if r.File != "" {
+		buf.WriteString(`,"log.origin":{"file.name":`)
+		writeJSONValue(buf, path.Base(r.File))
+		buf.WriteString(`,"file.line":`)
+		writeJSONValue(buf, r.Line)
+		buf.WriteByte('}')
+	}
+
+	fields := r.Fields
+	for i, f := range fields {
+		err, ok := f.Value.(error)
+		if !ok {
+			continue
+		}
+		buf.WriteString(`,"error":{"message":`)
+		writeJSONValue(buf, err.Error())
+		buf.WriteString(`,"type":`)
+		writeJSONValue(buf, fmt.Sprintf("%T", err))
+		// 兼容 github.com/pkg/errors 等通过 %+v 输出堆栈的错误
+		if stack := fmt.Sprintf("%+v", err); stack != err.Error() {
+			buf.WriteString(`,"stack_trace":`)
+			writeJSONValue(buf, stack)
+		}
+		buf.WriteByte('}')
+		fields = append(fields[:i:i], fields[i+1:]...)
+		break
+	}
+	if len(fields) == 0 {
+		buf.WriteByte('}')
+		return
+	}
+	if namespace != "" {
+		buf.WriteString(`,`)
+		writeJSONValue(buf, namespace)
+		buf.WriteString(`:{`)
+	}
+	for i, f := range fields {
+		if i > 0 || namespace == "" {
+			buf.WriteByte(',')
+		}
+		writeJSONValue(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, f.Value)
+	}
+	if namespace != "" {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stackError 模拟 github.com/pkg/errors 的错误，%+v 输出堆栈
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.msg+"\nmain.run\n\tmain.go:12")
+		return
+	}
+	_, _ = io.WriteString(s, e.msg)
+}
+
+func TestECSFormat(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithFormat(FormatECS),
+				WithConsole(ConsoleNone),
+				WithName("orders"),
+				WithAddSource(),
+				WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Error("save failed", "order_id", 42, "err", &stackError{msg: "db timeout"})
+
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(out.String()), &entry); err != nil {
+				t.Fatalf("output is not json: %q", out.String())
+			}
+			if entry["@timestamp"] == nil || entry["log.level"] != "error" || entry["message"] != "save failed" || entry["log.logger"] != "orders" {
+				t.Fatalf("unexpected base fields: %v", entry)
+			}
+			origin, _ := entry["log.origin"].(map[string]any)
+			if origin["file.name"] != "ecs_test.go" || origin["file.line"] == nil {
+				t.Fatalf("unexpected log.origin: %v", entry["log.origin"])
+			}
+			errorField, _ := entry["error"].(map[string]any)
+			if errorField["message"] != "db timeout" || !strings.Contains(fmt.Sprint(errorField["stack_trace"]), "main.go:12") {
+				t.Fatalf("unexpected error: %v", entry["error"])
+			}
+			if labels, _ := entry["labels"].(map[string]any); labels["order_id"] != float64(42) || labels["err"] != nil {
+				t.Fatalf("unexpected labels: %v", entry["labels"])
+			}
+		})
+	}
+}
+
+func TestECSNamespace(t *testing.T) {
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(SlogLogger, WithFormat(FormatECS), WithConsole(ConsoleNone), WithECSNamespace("app"), WithOutput(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Warn("retry", "attempt", 2, "err", errors.New("refused"))
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(out.String()), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := entry["app"].(map[string]any); app["attempt"] != float64(2) {
+		t.Fatalf("unexpected namespace: %v", entry)
+	}
+	if errorField, _ := entry["error"].(map[string]any); errorField["message"] != "refused" || errorField["stack_trace"] != nil {
+		t.Fatalf("unexpected error: %v", entry["error"])
+	}
+}
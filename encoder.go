@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/piwriw/go-logger/internal/textenc"
+)
+
+// Encoder 自定义日志编码器，通过 WithEncoder 设置后所有日志实现的输出都使用该编码器
+// Encode 返回一条日志，结尾没有换行时写入时自动追加换行；实现需要保证并发安全
+// Record 以值传递，Fields 为副本，修改不会影响其他输出
+type Encoder interface {
+	Encode(r Record) ([]byte, error)
+}
+
+// EncoderFunc 将函数适配为 Encoder
+type EncoderFunc func(r Record) ([]byte, error)
+
+// Encode 调用 f(r)
+func (f EncoderFunc) Encode(r Record) ([]byte, error) {
+	return f(r)
+}
+
+// WithEncoder sets a custom encoder used by every backend
+// WithEncoder 使用自定义编码器，日志格式设置为 FormatCustom，控制台、文件、错误日志文件、轮转文件和额外的输出都使用该编码器
+// 可以通过 WithOutputFormat 为某个输出单独设置其他格式，例如控制台使用 FormatPretty
+func WithEncoder(encoder Encoder) Option {
+	return func(o *Options) {
+		o.encoder = encoder
+		o.Format = FormatCustom
+	}
+}
+
+// recordEncoder 将 Record 编码为一行日志，供路由等基于 Record 的输出使用
+// 与 Options 保持一致：Format 决定 JSON、logfmt、ECS 或 key=value 文本，时间使用 TimeFormat 和 TimeZone
+type recordEncoder struct {
+	format       Format
+	timeFormat   string
+	location     *time.Location
+	ecsNamespace string
+	schema       *Schema
+	security     *SecurityEventConfig
+	color        *ColorScheme // 只用于文本和 FormatPretty
+	indent       bool         // MultilineIndent，只用于文本格式
+	custom       Encoder      // 只用于 FormatCustom
+}
+
+func newRecordEncoder(opts Options) (*recordEncoder, error) {
+	location, err := time.LoadLocation(opts.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	timeFormat := opts.TimeFormat
+	if timeFormat == "" {
+		timeFormat = defaultTimeFormat
+	}
+	return &recordEncoder{
+		format:       opts.Format,
+		timeFormat:   timeFormat,
+		location:     location,
+		ecsNamespace: opts.ecsNamespace,
+		schema:       opts.recordSchema(),
+		security:     opts.securityEventConfig(),
+		custom:       opts.encoder,
+		indent:       opts.multiline == MultilineIndent,
+	}, nil
+}
+
+// encode 编码日志记录，结果以换行结尾，二进制格式除外
+// 只有自定义编码器会返回错误，未设置自定义编码器时 FormatCustom 使用文本格式
+func (e *recordEncoder) encode(r *Record) ([]byte, error) {
+	switch {
+	case e.format == FormatBinary:
+		return appendRecordBinary(nil, r), nil
+	case e.format == FormatCustom && e.custom != nil:
+		// 同一条 Record 会分发给多个输出，传入副本
+		record := *r
+		record.Fields = slices.Clone(r.Fields)
+		data, err := e.custom.Encode(record)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 || data[len(data)-1] != '\n' {
+			data = append(data[:len(data):len(data)], '\n')
+		}
+		return data, nil
+	}
+	var buf bytes.Buffer
+	timestamp := r.Time.In(e.location).Format(e.timeFormat)
+	schema := e.schema
+	if schema == nil {
+		schema = &defaultSchema
+	}
+	switch e.format {
+	case FormatJSON:
+		appendRecordJSON(&buf, r, timestamp, schema)
+	case FormatLogfmt:
+		appendRecordLogfmt(&buf, r, timestamp, schema)
+	case FormatECS:
+		appendRecordECS(&buf, r, e.location, e.ecsNamespace)
+	case FormatCEF:
+		appendRecordCEF(&buf, r, e.security)
+	case FormatLEEF:
+		appendRecordLEEF(&buf, r, e.location, e.security)
+	case FormatPretty:
+		appendRecordPretty(&buf, r, timestamp, e.color)
+	default:
+		appendRecordText(&buf, r, timestamp, schema, e.color, e.indent)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// appendRecordText 编码为 key=value 文本，与 slog.TextHandler 的格式一致
+// scheme 不为 nil 时按级别为级别的值着色；indent 为 true 时字符串中以制表符缩进的后续行原样换行，不转义
+func appendRecordText(buf *bytes.Buffer, r *Record, timestamp string, schema *Schema, scheme *ColorScheme, indent bool) {
+	header, fields := schema.header(r, timestamp, r.Level.String())
+	for i, f := range header {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		if f.Key == schema.LevelKey && scheme != nil {
+			var level bytes.Buffer
+			writeTextValue(&level, f.Value)
+			textenc.WriteString(buf, f.Key)
+			buf.WriteByte('=')
+			buf.WriteString(scheme.Colorize(r.Level, level.String()))
+			continue
+		}
+		writeTextField(buf, f.Key, f.Value, indent)
+	}
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		writeTextField(buf, schema.fieldKey(f.Key), f.Value, indent)
+	}
+}
+
+// writeTextField indent 为 true 且值为多行字符串时，加引号并保留换行和缩进，其余与 writeTextPair 相同
+func writeTextField(buf *bytes.Buffer, key string, value any, indent bool) {
+	s, ok := value.(string)
+	if !indent || !ok || !strings.Contains(s, "\n") {
+		writeTextPair(buf, key, value)
+		return
+	}
+	textenc.WriteString(buf, key)
+	buf.WriteString(`="`)
+	for i, line := range strings.Split(s, "\n\t") {
+		if i > 0 {
+			buf.WriteString("\n\t")
+		}
+		quoted := strconv.Quote(line)
+		buf.WriteString(quoted[1 : len(quoted)-1])
+	}
+	buf.WriteByte('"')
+}
+
+// appendRecordLogfmt 编码为 logfmt
+// 与文本格式相比，级别使用小写，key 中的非法字符替换为下划线，复合类型的值编码为 JSON
+func appendRecordLogfmt(buf *bytes.Buffer, r *Record, timestamp string, schema *Schema) {
+	header, fields := schema.header(r, timestamp, strings.ToLower(r.Level.String()))
+	for i, f := range header {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		writeLogfmtPair(buf, f.Key, f.Value)
+	}
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		writeLogfmtPair(buf, schema.fieldKey(f.Key), f.Value)
+	}
+}
+
+func writeLogfmtPair(buf *bytes.Buffer, key string, value any) {
+	writeLogfmtKey(buf, key)
+	buf.WriteByte('=')
+	switch v := value.(type) {
+	case nil:
+		// 空值
+	case string:
+		textenc.WriteString(buf, v)
+	case []byte:
+		textenc.WriteString(buf, string(v))
+	case error:
+		textenc.WriteString(buf, v.Error())
+	case time.Time:
+		buf.WriteString(v.Format(time.RFC3339Nano))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		fmt.Fprint(buf, v)
+	case fmt.Stringer:
+		textenc.WriteString(buf, v.String())
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			textenc.WriteString(buf, fmt.Sprint(v))
+			return
+		}
+		textenc.WriteString(buf, string(data))
+	}
+}
+
+// writeLogfmtKey key 不能为空，也不能包含空白、等号、引号和控制字符
+func writeLogfmtKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f || unicode.IsSpace(c) || !unicode.IsPrint(c) {
+			buf.WriteByte('_')
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+}
+
+func writeTextPair(buf *bytes.Buffer, key string, value any) {
+	textenc.WriteString(buf, key)
+	buf.WriteByte('=')
+	writeTextValue(buf, value)
+}
+
+func writeTextValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case string:
+		textenc.WriteString(buf, v)
+	case error:
+		textenc.WriteString(buf, v.Error())
+	case fmt.Stringer:
+		textenc.WriteString(buf, v.String())
+	default:
+		textenc.WriteString(buf, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pipeEncoder 竖线分隔的日志格式：级别|日志名称|日志内容|字段
+var pipeEncoder = EncoderFunc(func(r Record) ([]byte, error) {
+	parts := []string{r.Level.String(), r.Logger, r.Message}
+	for _, f := range r.Fields {
+		parts = append(parts, fmt.Sprintf("%s:%v", f.Key, f.Value))
+	}
+	return []byte(strings.Join(parts, "|")), nil
+})
+
+func TestCustomEncoder(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			dir := t.TempDir()
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithEncoder(pipeEncoder),
+				WithConsole(ConsoleNone),
+				WithName("billing"),
+				WithFileOutput(filepath.Join(dir, "app.log")),
+				WithOutputFormat(OutputFile, FormatJSON),
+				WithOutput(out),
+				WithMark())
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.WithFields(map[string]any{"tenant": "acme"}).Info("invoice paid", "amount", 42, "password", "secret")
+			logger.Debug("hidden")
+
+			want := "INFO|billing|invoice paid|tenant:acme|amount:42|password:[****]\n"
+			if out.String() != want {
+				t.Fatalf("output = %q, want %q", out.String(), want)
+			}
+			// 单独设置格式的输出不受影响
+			data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+			if strings.Contains(string(data), "|") || !strings.Contains(string(data), `"tenant":"acme"`) {
+				t.Fatalf("file output = %q", data)
+			}
+		})
+	}
+}
+
+func TestCustomEncoderError(t *testing.T) {
+	encoder := EncoderFunc(func(r Record) ([]byte, error) {
+		return nil, errors.New("unsupported record")
+	})
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(SlogLogger, WithEncoder(encoder), WithConsole(ConsoleNone), WithOutput(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("dropped")
+	if out.String() != "" {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestCustomEncoderReceivesCopy(t *testing.T) {
+	encoder := EncoderFunc(func(r Record) ([]byte, error) {
+		r.Message = "changed"
+		r.Fields[0].Value = "changed"
+		return pipeEncoder(r)
+	})
+	ring := NewRingBufferSink(RingBufferConfig{DisableAutoDump: true})
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(SlogLogger, WithEncoder(encoder), WithConsole(ConsoleNone), WithOutput(out), WithRingBuffer(ring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("paid", "tenant", "acme")
+
+	if out.String() != "INFO||changed|tenant:changed\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+	// 编码器的修改不影响其他输出
+	records := ring.Records()
+	if len(records) != 1 || records[0].Message != "paid" || records[0].Fields[0].Value != "acme" {
+		t.Fatalf("record modified by encoder: %+v", records)
+	}
+}
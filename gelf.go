@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultGELFChunkSize = 1420 // 适配常见 MTU
+	gelfMaxChunks        = 128
+)
+
+// gelfChunkMagic GELF 分块消息头
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// GELFCompression GELF UDP 消息压缩方式
+type GELFCompression int
+
+const (
+	GELFCompressGzip GELFCompression = iota // gzip 压缩，默认
+	GELFCompressZlib                        // zlib 压缩
+	GELFCompressNone                        // 不压缩
+)
+
+// GELFConfig GELF 输出配置
+type GELFConfig struct {
+	// Network 网络类型：udp 或 tcp
+	Network string
+	// Addr Graylog GELF input 地址
+	Addr string
+	// Host 来源主机名，为空时使用 os.Hostname
+	Host string
+	// ChunkSize UDP 单个分块的最大字节数，默认 1420
+	ChunkSize int
+	// Compression UDP 消息压缩方式，TCP 不支持压缩
+	Compression GELFCompression
+}
+
+// GELFSink GELF 1.1 日志输出端
+// UDP 消息超过 ChunkSize 时自动分块，TCP 消息以空字节分隔
+type GELFSink struct {
+	cfg  GELFConfig
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+var _ Sink = (*GELFSink)(nil)
+
+// NewGELFSink 创建 GELF 输出端，UDP 使用 gzip 压缩
+// 日志级别映射为 syslog 严重性，调用信息写入 _file/_line，字段写入以下划线开头的附加字段，id、file、line 字段写入 __id、__file、__line
+// 通过 WithSink 添加到日志实例，输出端由调用方持有，退出前需要调用 Close 关闭连接，例如：
+//
+//	sink, err := logger.NewGELFSink(logger.GELFConfig{Network: "udp", Addr: "graylog:12201"})
+//	log, err := logger.NewLogger(logger.WithSink(sink))
+//	defer sink.Close()
+func NewGELFSink(cfg GELFConfig) (*GELFSink, error) {
+	switch cfg.Network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("gelf: unsupported network %q", cfg.Network)
+	}
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	if cfg.ChunkSize <= 12 {
+		cfg.ChunkSize = defaultGELFChunkSize
+	}
+	s := &GELFSink{cfg: cfg}
+	conn, err := net.Dial(cfg.Network, cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	s.conn = conn
+	return s, nil
+}
+
+// WriteRecord 发送一条 GELF 消息，写入失败时重连一次
+func (s *GELFSink) WriteRecord(r *Record) error {
+	data, err := s.encode(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		if err = s.write(data); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	conn, err := net.Dial(s.cfg.Network, s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return s.write(data)
+}
+
+// Close 关闭连接
+func (s *GELFSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// encode 编码 GELF 1.1 JSON 消息
+func (s *GELFSink) encode(r *Record) ([]byte, error) {
+	msg := map[string]any{
+		"version":       "1.1",
+		"host":          s.cfg.Host,
+		"short_message": r.Message,
+		"timestamp":     float64(r.Time.UnixMicro()) / 1e6,
+		"level":         ToSyslogSeverity(r.Level),
+	}
+	// 多行消息首行作为 short_message，完整内容放入 full_message
+	if first, _, multiline := strings.Cut(r.Message, "\n"); multiline {
+		msg["short_message"] = first
+		msg["full_message"] = r.Message
+	}
+	if msg["short_message"] == "" {
+		msg["short_message"] = "-" // short_message 不允许为空
+	}
+	if r.File != "" {
+		msg["_file"] = r.File
+		msg["_line"] = r.Line
+	}
+	for _, f := range r.Fields {
+		msg[gelfFieldName(f.Key)] = gelfFieldValue(f.Value)
+	}
+	return json.Marshal(msg)
+}
+
+func (s *GELFSink) write(data []byte) error {
+	if isStreamNetwork(s.cfg.Network) {
+		// TCP 不支持压缩和分块，消息以空字节结尾
+		_, err := s.conn.Write(append(data, 0))
+		return err
+	}
+	data, err := s.compress(data)
+	if err != nil {
+		return err
+	}
+	if len(data) <= s.cfg.ChunkSize {
+		_, err = s.conn.Write(data)
+		return err
+	}
+	return s.writeChunks(data)
+}
+
+func (s *GELFSink) compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch s.cfg.Compression {
+	case GELFCompressGzip:
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case GELFCompressZlib:
+		zw := zlib.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// writeChunks 分块发送：魔数(2) + 消息 ID(8) + 序号(1) + 总数(1) + 数据
+func (s *GELFSink) writeChunks(data []byte) error {
+	payloadSize := s.cfg.ChunkSize - 12
+	count := (len(data) + payloadSize - 1) / payloadSize
+	if count > gelfMaxChunks {
+		return errors.New("gelf: message too large")
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, s.cfg.ChunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * payloadSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk = append(chunk[:0], gelfChunkMagic...)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*payloadSize:end]...)
+		if _, err := s.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gelfFieldName 附加字段名以下划线开头，只允许字母、数字、下划线、点和短横线，_id、_file、_line 为保留字段
+func gelfFieldName(key string) string {
+	var b strings.Builder
+	b.WriteByte('_')
+	for _, c := range key {
+		if c == '_' || c == '.' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	// _file 和 _line 用于调用信息，与 _id 一样在前面再加一个下划线，避免与用户字段重名
+	switch name := b.String(); name {
+	case "_id", "_file", "_line":
+		return "_" + name
+	default:
+		return name
+	}
+}
+
+// gelfFieldValue 附加字段只支持字符串和数字
+func gelfFieldValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return val
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val
+	case error:
+		return val.Error()
+	default:
+		return fmt.Sprint(val)
+	}
+}
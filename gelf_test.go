@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGELFPacket 读取一条 GELF UDP 消息，支持分块和 gzip 压缩
+func readGELFPacket(t *testing.T, conn net.PacketConn) map[string]any {
+	t.Helper()
+	chunks := map[byte][]byte{}
+	buf := make([]byte, 65535)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packet := append([]byte(nil), buf[:n]...)
+		if !bytes.HasPrefix(packet, gelfChunkMagic) {
+			return decodeGELF(t, packet)
+		}
+		chunks[packet[10]] = packet[12:]
+		if len(chunks) == int(packet[11]) {
+			keys := make([]int, 0, len(chunks))
+			for k := range chunks {
+				keys = append(keys, int(k))
+			}
+			sort.Ints(keys)
+			var data []byte
+			for _, k := range keys {
+				data = append(data, chunks[byte(k)]...)
+			}
+			return decodeGELF(t, data)
+		}
+	}
+}
+
+func decodeGELF(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestGELFOutputUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Network: "udp", Addr: conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger, err := NewLoggerWithType(ZapLogger,
+		WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+		WithAddSource(),
+		WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WithFields(map[string]any{"id": 7, "tenant": "acme"}).Error("query failed\nstack trace", "err", errors.New("timeout"))
+
+	msg := readGELFPacket(t, conn)
+	if msg["version"] != "1.1" || msg["level"] != float64(3) {
+		t.Fatalf("unexpected header fields: %v", msg)
+	}
+	if msg["short_message"] != "query failed" || msg["full_message"] != "query failed\nstack trace" {
+		t.Fatalf("unexpected message: %v", msg)
+	}
+	if msg["_file"] == nil || !strings.HasSuffix(msg["_file"].(string), "gelf_test.go") || msg["_line"] == nil {
+		t.Fatalf("source info missing: %v", msg)
+	}
+	if msg["__id"] != float64(7) || msg["_tenant"] != "acme" || msg["_err"] != "timeout" {
+		t.Fatalf("additional fields missing: %v", msg)
+	}
+}
+
+func TestGELFOutputUDPChunked(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewGELFSink(GELFConfig{Network: "udp", Addr: conn.LocalAddr().String(), ChunkSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	// 随机性较强的内容压缩后仍然大于一个分块
+	var payload strings.Builder
+	for i := 0; i < 200; i++ {
+		payload.WriteString(time.Now().Add(time.Duration(i) * time.Hour).Format(time.RFC3339Nano))
+	}
+	if err := sink.WriteRecord(&Record{Time: time.Now(), Level: InfoLevel, Message: payload.String()}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readGELFPacket(t, conn); msg["short_message"] != payload.String() {
+		t.Fatalf("chunked message mismatch: %v", msg["short_message"])
+	}
+}
+
+func TestGELFOutputTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		frame, _ := bufio.NewReader(conn).ReadBytes(0)
+		received <- frame
+	}()
+
+	sink, err := NewGELFSink(GELFConfig{Network: "tcp", Addr: ln.Addr().String(), Host: "web-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.WriteRecord(&Record{Time: time.Now(), Level: WarnLevel, Message: "slow request"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case frame := <-received:
+		var msg map[string]any
+		if err := json.Unmarshal(bytes.TrimSuffix(frame, []byte{0}), &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg["host"] != "web-1" || msg["level"] != float64(4) || msg["short_message"] != "slow request" {
+			t.Fatalf("unexpected message: %v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for gelf message")
+	}
+}
+
+func TestGELFReservedFields(t *testing.T) {
+	s := &GELFSink{cfg: GELFConfig{Host: "test"}}
+	data, err := s.encode(&Record{
+		Time:    time.Now(),
+		Level:   InfoLevel,
+		Message: "upload",
+		File:    "/src/app/main.go",
+		Line:    12,
+		Fields:  []Field{{Key: "file", Value: "report.csv"}, {Key: "line", Value: 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	// 用户字段不能覆盖调用信息
+	if msg["_file"] != "/src/app/main.go" || msg["_line"] != float64(12) {
+		t.Fatalf("caller overwritten: %v", msg)
+	}
+	if msg["__file"] != "report.csv" || msg["__line"] != float64(3) {
+		t.Fatalf("user fields not renamed: %v", msg)
+	}
+}
@@ -0,0 +1,315 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHTTPBatchSize     = 100
+	defaultHTTPBatchBytes    = 1 << 20 // 1MB
+	defaultHTTPFlushInterval = time.Second
+	defaultHTTPQueueSize     = 10000
+	defaultHTTPMaxRetries    = 3
+	defaultHTTPMinBackoff    = 100 * time.Millisecond
+	defaultHTTPMaxBackoff    = 10 * time.Second
+)
+
+// ErrSinkClosed 输出端已关闭
+var ErrSinkClosed = errors.New("sink is closed")
+
+// ErrQueueFull 发送队列已满，日志被丢弃
+var ErrQueueFull = errors.New("sink queue is full")
+
+// HTTPSinkConfig HTTP 批量发送配置
+type HTTPSinkConfig struct {
+	// URL 接收日志的地址，每批日志以 NDJSON 格式 POST
+	URL string
+	// Headers 自定义请求头，例如鉴权信息
+	Headers map[string]string
+	// Client HTTP 客户端，默认使用超时 10s 的客户端
+	Client *http.Client
+	// Gzip 是否使用 gzip 压缩请求体
+	Gzip bool
+	// BatchSize 每批最多条数，默认 100
+	BatchSize int
+	// BatchBytes 每批最大字节数，默认 1MB
+	BatchBytes int
+	// FlushInterval 日志最长等待时间，默认 1s
+	FlushInterval time.Duration
+	// QueueSize 待发送队列长度，默认 10000，队列满时丢弃日志
+	QueueSize int
+	// MaxRetries 失败重试次数，默认 3，小于 0 时不重试
+	MaxRetries int
+	// MinBackoff 重试最小间隔，默认 100ms，每次重试翻倍
+	MinBackoff time.Duration
+	// MaxBackoff 重试最大间隔，默认 10s
+	MaxBackoff time.Duration
+	// DeadLetterPath 重试失败的批次以 NDJSON 追加写入该文件，为空时丢弃
+	DeadLetterPath string
+}
+
+// HTTPSink HTTP 批量发送输出端
+// 日志按条数、字节数和最长等待时间分批，以 NDJSON 格式发送到日志采集服务
+type HTTPSink struct {
+	cfg     HTTPSinkConfig
+	queue   chan []byte
+	flushCh chan chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	closeMu sync.RWMutex // 检查 closed 和加入队列在读锁内完成，Close 持有写锁设置 closed，保证关闭后不再有日志加入队列
+	closed  bool
+	dropped atomic.Uint64
+	mu      sync.Mutex // 保护 deadLetter 写入
+
+	// 以下字段用于复用批量发送逻辑实现其他协议，例如 OTLP/HTTP
+	encodeRecord func(r *Record) ([]byte, error) // 编码一条日志，结果不能包含换行
+	frameBatch   func(batch []byte) []byte       // 将换行分隔的一批日志转换为请求体
+	contentType  string
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// NewHTTPSink 创建 HTTP 批量发送输出端，退出前需要调用 Close 发送剩余日志
+func NewHTTPSink(cfg HTTPSinkConfig) (*HTTPSink, error) {
+	return newHTTPSink(cfg, (*Record).MarshalJSON, nil, "application/x-ndjson")
+}
+
+// newHTTPSink 创建使用指定编码的批量发送输出端，frame 为 nil 时请求体为 NDJSON
+func newHTTPSink(cfg HTTPSinkConfig, encode func(*Record) ([]byte, error), frame func([]byte) []byte, contentType string) (*HTTPSink, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("http sink requires url")
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultHTTPBatchSize
+	}
+	if cfg.BatchBytes <= 0 {
+		cfg.BatchBytes = defaultHTTPBatchBytes
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultHTTPFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultHTTPQueueSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultHTTPMaxRetries
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultHTTPMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = defaultHTTPMaxBackoff
+	}
+	s := &HTTPSink{
+		cfg:     cfg,
+		queue:   make(chan []byte, cfg.QueueSize),
+		flushCh: make(chan chan struct{}),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+
+		encodeRecord: encode,
+		frameBatch:   frame,
+		contentType:  contentType,
+	}
+	go s.run()
+	return s, nil
+}
+
+// WriteRecord 将日志记录加入发送队列，队列满时返回 ErrQueueFull
+func (s *HTTPSink) WriteRecord(r *Record) error {
+	line, err := s.encodeRecord(r)
+	if err != nil {
+		return err
+	}
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+	select {
+	case s.queue <- append(line, '\n'):
+		return nil
+	default:
+		s.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Flush 立即发送队列中的日志并等待完成
+func (s *HTTPSink) Flush() {
+	ack := make(chan struct{})
+	select {
+	case s.flushCh <- ack:
+		<-ack
+	case <-s.done:
+	}
+}
+
+// Dropped 返回因队列已满被丢弃的日志条数
+func (s *HTTPSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 发送剩余日志并停止后台发送
+func (s *HTTPSink) Close() error {
+	s.once.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		s.closeMu.Unlock()
+		close(s.closing)
+		<-s.done
+	})
+	return nil
+}
+
+func (s *HTTPSink) run() {
+	defer close(s.done)
+	var batch bytes.Buffer
+	count := 0
+	timer := time.NewTimer(s.cfg.FlushInterval)
+	timer.Stop()
+
+	send := func() {
+		timer.Stop()
+		if count == 0 {
+			return
+		}
+		s.send(batch.Bytes())
+		batch.Reset()
+		count = 0
+	}
+	add := func(line []byte) {
+		// 单批超过字节上限时先发送已有的日志
+		if count > 0 && batch.Len()+len(line) > s.cfg.BatchBytes {
+			send()
+		}
+		if count == 0 {
+			timer.Reset(s.cfg.FlushInterval)
+		}
+		batch.Write(line)
+		count++
+		if count >= s.cfg.BatchSize || batch.Len() >= s.cfg.BatchBytes {
+			send()
+		}
+	}
+
+	for {
+		select {
+		case line := <-s.queue:
+			add(line)
+		case <-timer.C:
+			send()
+		case ack := <-s.flushCh:
+			s.drainQueue(add)
+			send()
+			close(ack)
+		case <-s.closing:
+			s.drainQueue(add)
+			send()
+			return
+		}
+	}
+}
+
+func (s *HTTPSink) drainQueue(add func([]byte)) {
+	for {
+		select {
+		case line := <-s.queue:
+			add(line)
+		default:
+			return
+		}
+	}
+}
+
+// send 发送一批日志，失败时按指数退避重试，最终失败写入死信文件
+func (s *HTTPSink) send(batch []byte) {
+	backoff := s.cfg.MinBackoff
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > s.cfg.MaxBackoff {
+				backoff = s.cfg.MaxBackoff
+			}
+		}
+		var retryable bool
+		retryable, err = s.post(batch)
+		if err == nil || !retryable {
+			break
+		}
+	}
+	if err != nil {
+		s.deadLetter(batch)
+	}
+}
+
+// post 发送请求，返回错误是否可以重试
+func (s *HTTPSink) post(batch []byte) (bool, error) {
+	body := batch
+	if s.frameBatch != nil {
+		body = s.frameBatch(batch)
+	}
+	if s.cfg.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return false, err
+		}
+		body = buf.Bytes()
+	}
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	if s.cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("http sink: unexpected status %s", resp.Status)
+	// 限流和服务端错误可以重试，其余客户端错误重试也不会成功
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+func (s *HTTPSink) deadLetter(batch []byte) {
+	if s.cfg.DeadLetterPath == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.cfg.DeadLetterPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	_, _ = file.Write(batch)
+}
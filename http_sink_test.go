@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ndjsonCollector 模拟日志采集服务，记录每批收到的日志
+type ndjsonCollector struct {
+	mu      sync.Mutex
+	batches [][]map[string]any
+	headers []http.Header
+}
+
+func (c *ndjsonCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	var batch []map[string]any
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		batch = append(batch, record)
+	}
+	c.mu.Lock()
+	c.batches = append(c.batches, batch)
+	c.headers = append(c.headers, r.Header.Clone())
+	c.mu.Unlock()
+}
+
+func (c *ndjsonCollector) snapshot() ([][]map[string]any, []http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]map[string]any(nil), c.batches...), append([]http.Header(nil), c.headers...)
+}
+
+func TestHTTPSinkBatchByCount(t *testing.T) {
+	collector := &ndjsonCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{
+		URL:           server.URL,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+		Gzip:          true,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLoggerWithType(SlogLogger, WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithSink(sink), WithMark())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("first", "password", "secret")
+	logger.Warn("second")
+	logger.Error("third")
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	batches, headers := collector.snapshot()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	first := batches[0][0]
+	if first["msg"] != "first" || first["level"] != "INFO" || first["password"] != "[****]" {
+		t.Fatalf("unexpected record: %v", first)
+	}
+	if headers[0].Get("Authorization") != "Bearer token" || headers[0].Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("unexpected headers: %v", headers[0])
+	}
+}
+
+func TestHTTPSinkFlushInterval(t *testing.T) {
+	collector := &ndjsonCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	_ = sink.WriteRecord(&Record{Time: time.Now(), Level: InfoLevel, Message: "waiting"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if batches, _ := collector.snapshot(); len(batches) == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("batch was not flushed after FlushInterval")
+}
+
+func TestHTTPSinkRetry(t *testing.T) {
+	collector := &ndjsonCollector{}
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		collector.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, MinBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.WriteRecord(&Record{Time: time.Now(), Level: InfoLevel, Message: "retried"})
+	sink.Flush()
+	_ = sink.Close()
+
+	if attempts.Load() != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts.Load())
+	}
+	if batches, _ := collector.snapshot(); len(batches) != 1 || batches[0][0]["msg"] != "retried" {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+}
+
+func TestHTTPSinkDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead.ndjson")
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, DeadLetterPath: deadLetter, MinBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.WriteRecord(&Record{Time: time.Now(), Level: ErrorLevel, Message: "rejected"})
+	_ = sink.Close()
+
+	data, err := os.ReadFile(deadLetter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"msg":"rejected"`) {
+		t.Fatalf("dead letter = %s", data)
+	}
+	if err := sink.WriteRecord(&Record{}); err != ErrSinkClosed {
+		t.Fatalf("err = %v, want ErrSinkClosed", err)
+	}
+}
+
+func TestHTTPSinkWriteDuringClose(t *testing.T) {
+	collector := &ndjsonCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+	sink, err := NewHTTPSink(HTTPSinkConfig{URL: server.URL, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 与 Close 并发写入时，返回 nil 的日志都必须发送出去
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if sink.WriteRecord(&Record{Time: time.Now(), Level: InfoLevel, Message: "m"}) == nil {
+					accepted.Add(1)
+				}
+			}
+		}()
+	}
+	_ = sink.Close()
+	wg.Wait()
+
+	batches, _ := collector.snapshot()
+	received := 0
+	for _, batch := range batches {
+		received += len(batch)
+	}
+	if int64(received) != accepted.Load() {
+		t.Fatalf("received %d records, accepted %d", received, accepted.Load())
+	}
+}
@@ -0,0 +1,41 @@
+// Package textenc 文本（logfmt）格式的字符串引号规则，logger 和 binlog 共用，保证两处输出一致
+package textenc
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WriteString 包含空格、等号、引号或不可打印字符的字符串需要加引号
+func WriteString(buf *bytes.Buffer, s string) {
+	if NeedsQuoting(s) {
+		buf.WriteString(strconv.Quote(s))
+		return
+	}
+	buf.WriteString(s)
+}
+
+// NeedsQuoting 空字符串、包含空白、等号、引号、控制字符、非法 UTF-8 或不可打印字符时返回 true
+func NeedsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// 默认 journald 原生协议套接字
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldConfig journald 输出配置
+type JournaldConfig struct {
+	// SocketPath journald 套接字路径，默认 /run/systemd/journal/socket
+	SocketPath string
+	// Identifier SYSLOG_IDENTIFIER 字段，为空时使用进程名
+	Identifier string
+}
+
+// JournaldSink systemd journal 原生协议输出端
+// 每条日志作为一个数据报发送，PRIORITY、CODE_FILE、CODE_LINE 以及每个字段都是独立的 journal 字段
+type JournaldSink struct {
+	cfg  JournaldConfig
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+var _ Sink = (*JournaldSink)(nil)
+
+// NewJournaldSink 创建 journald 输出端
+// 通过 WithSink 添加到日志实例，输出端由调用方持有，退出前需要调用 Close 关闭连接，例如：
+//
+//	sink, err := logger.NewJournaldSink(logger.JournaldConfig{})
+//	log, err := logger.NewLogger(logger.WithSink(sink))
+//	defer sink.Close()
+func NewJournaldSink(cfg JournaldConfig) (*JournaldSink, error) {
+	if cfg.SocketPath == "" {
+		cfg.SocketPath = defaultJournaldSocket
+	}
+	if cfg.Identifier == "" {
+		cfg.Identifier = filepath.Base(os.Args[0])
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: cfg.SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournaldSink{cfg: cfg, conn: conn}, nil
+}
+
+// WriteRecord 发送一条日志，超过数据报大小限制时通过文件描述符传递
+func (s *JournaldSink) WriteRecord(r *Record) error {
+	data := s.encode(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.conn.Write(data)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFD(s.conn, data)
+	}
+	return err
+}
+
+// Close 关闭连接
+func (s *JournaldSink) Close() error {
+	return s.conn.Close()
+}
+
+// encode 编码 journald 原生协议
+// 单行值编码为 KEY=value\n，包含换行的值编码为 KEY\n + 8 字节小端长度 + value + \n
+func (s *JournaldSink) encode(r *Record) []byte {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "MESSAGE", r.Message)
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(ToSyslogSeverity(r.Level)))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", s.cfg.Identifier)
+	if r.File != "" {
+		writeJournalField(&buf, "CODE_FILE", r.File)
+		writeJournalField(&buf, "CODE_LINE", strconv.Itoa(r.Line))
+	}
+	for _, f := range r.Fields {
+		writeJournalField(&buf, journalFieldName(f.Key), fmt.Sprint(f.Value))
+	}
+	return buf.Bytes()
+}
+
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName 字段名只允许大写字母、数字和下划线，不能以下划线或数字开头，最长 64 个字符
+// 与本输出端写入的 MESSAGE、PRIORITY、SYSLOG_IDENTIFIER、CODE_FILE、CODE_LINE 重名时加 FIELD_ 前缀，避免出现重复的字段
+func journalFieldName(key string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(key) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := strings.TrimLeft(b.String(), "_")
+	switch {
+	case name == "" || (name[0] >= '0' && name[0] <= '9'):
+		name = "FIELD_" + name
+	case name == "MESSAGE" || name == "PRIORITY" || name == "SYSLOG_IDENTIFIER" || name == "CODE_FILE" || name == "CODE_LINE":
+		name = "FIELD_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
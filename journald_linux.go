@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// sendJournalFD 将超大日志写入临时文件，通过 SCM_RIGHTS 传递文件描述符给 journald
+func sendJournalFD(conn *net.UnixConn, data []byte) error {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+	file, err := os.CreateTemp(dir, "journal.*")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// 删除文件名，journald 读取完成后文件自动释放
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), nil)
+	return err
+}
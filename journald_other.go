@@ -0,0 +1,13 @@
+//go:build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+// sendJournalFD 只有 Linux 支持通过文件描述符传递超大日志
+func sendJournalFD(conn *net.UnixConn, data []byte) error {
+	return errors.New("journald: message too large")
+}
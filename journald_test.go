@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseJournalFields 解析 journald 原生协议数据报
+func parseJournalFields(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		line, rest, ok := bytes.Cut(data, []byte{'\n'})
+		if !ok {
+			t.Fatalf("truncated datagram: %q", data)
+		}
+		if name, value, ok := bytes.Cut(line, []byte{'='}); ok {
+			fields[string(name)] = string(value)
+			data = rest
+			continue
+		}
+		size := binary.LittleEndian.Uint64(rest[:8])
+		fields[string(line)] = string(rest[8 : 8+size])
+		data = rest[8+size+1:]
+	}
+	return fields
+}
+
+func TestJournaldOutput(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewJournaldSink(JournaldConfig{SocketPath: socket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger, err := NewLoggerWithType(LogrusLogger,
+		WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+		WithAddSource(),
+		WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WithFields(map[string]any{"request-id": "r-1"}).Error("panic recovered\ngoroutine 1", "_uid", 0, "2fa", true)
+
+	buf := make([]byte, 65535)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournalFields(t, buf[:n])
+	want := map[string]string{
+		"MESSAGE":    "panic recovered\ngoroutine 1",
+		"PRIORITY":   "3",
+		"REQUEST_ID": "r-1",
+		"UID":        "0",
+		"FIELD_2FA":  "true",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s = %q, want %q", k, fields[k], v)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journald_test.go") || fields["CODE_LINE"] == "" {
+		t.Errorf("source fields missing: %v", fields)
+	}
+	if fields["SYSLOG_IDENTIFIER"] == "" {
+		t.Errorf("SYSLOG_IDENTIFIER missing: %v", fields)
+	}
+}
+
+func TestJournaldReservedFields(t *testing.T) {
+	s := &JournaldSink{cfg: JournaldConfig{Identifier: "api"}}
+	r := newRecord("", InfoLevel, "started", true, 1, nil,
+		[]any{"message", "user text", "priority", "high", "code_file", "x.go", "code_line", 7, "_syslog_identifier", "other"})
+	data := s.encode(r)
+	names := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		name, _, _ := strings.Cut(line, "=")
+		names[name]++
+	}
+	for name, count := range names {
+		if count != 1 {
+			t.Errorf("field %s written %d times:\n%s", name, count, data)
+		}
+	}
+	fields := parseJournalFields(t, data)
+	want := map[string]string{
+		"MESSAGE":                 "started",
+		"PRIORITY":                "6",
+		"SYSLOG_IDENTIFIER":       "api",
+		"FIELD_MESSAGE":           "user text",
+		"FIELD_PRIORITY":          "high",
+		"FIELD_CODE_FILE":         "x.go",
+		"FIELD_CODE_LINE":         "7",
+		"FIELD_SYSLOG_IDENTIFIER": "other",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s = %q, want %q", k, fields[k], v)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldKeys 标准字段的 key，为空时使用各日志实现的默认值（time、level、msg）
+type FieldKeys struct {
+	Time    string // 时间，例如 ts
+	Level   string // 级别，例如 severity
+	Message string // 日志内容，例如 message
+}
+
+// LevelFormat 日志级别的写法
+type LevelFormat int
+
+const (
+	LevelFormatDefault LevelFormat = iota // 默认写法，与 Record 一致使用大写，logfmt 格式使用小写
+	LevelFormatUpper                      // 大写，例如 INFO
+	LevelFormatLower                      // 小写，例如 info
+	LevelFormatShort                      // 首字母，例如 I
+	LevelFormatNumeric                    // 级别的数值，DebugLevel 为 0，FatalLevel 为 4
+)
+
+// WithFieldKeys sets the key names of time, level and message
+// WithFieldKeys 重命名时间、级别和日志内容的 key，对所有日志实现和输出生效，例如 ts、severity、message
+// 设置 WithNativeEncoder 时，zap 原生的文本格式按位置输出，没有 key，只有级别写法生效；klog 原生格式没有 key，设置后 klog 的文本输出仍由本库编码
+func WithFieldKeys(keys FieldKeys) Option {
+	return func(o *Options) {
+		o.fieldKeys = keys
+	}
+}
+
+// WithLevelFormat sets how the level is spelled
+// WithLevelFormat 设置日志级别的写法，对所有日志实现和输出生效，ECS 和 OTLP 等有固定规范的格式除外
+func WithLevelFormat(format LevelFormat) Option {
+	return func(o *Options) {
+		o.levelFormat = format
+	}
+}
+
+// customKeys 是否修改了标准字段的 key 或级别的写法
+func (o Options) customKeys() bool {
+	return o.fieldKeys != FieldKeys{} || o.levelFormat != LevelFormatDefault
+}
+
+// formatLevel 按 LevelFormat 返回级别的值，数值写法返回 int，其余返回 string
+// LevelFormatDefault 返回 def
+func formatLevel(level Level, format LevelFormat, def string) any {
+	switch format {
+	case LevelFormatUpper:
+		return level.String()
+	case LevelFormatLower:
+		return strings.ToLower(level.String())
+	case LevelFormatShort:
+		return level.String()[:1]
+	case LevelFormatNumeric:
+		return int(level)
+	default:
+		return def
+	}
+}
+
+// formatLevelString formatLevel 的字符串形式
+func formatLevelString(level Level, format LevelFormat, def string) string {
+	switch v := formatLevel(level, format, def).(type) {
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return v.(string)
+	}
+}
+
+// slogReplaceKeys 重命名 slog 的标准字段并转换级别写法，分组内的同名字段不受影响
+func slogReplaceKeys(keys FieldKeys, format LevelFormat, a slog.Attr) slog.Attr {
+	switch a.Key {
+	case slog.TimeKey:
+		if keys.Time != "" {
+			a.Key = keys.Time
+		}
+	case slog.LevelKey:
+		if format != LevelFormatDefault {
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				switch v := formatLevel(FromSlogLevel(level), format, "").(type) {
+				case int:
+					a.Value = slog.IntValue(v)
+				case string:
+					a.Value = slog.StringValue(v)
+				}
+			}
+		}
+		if keys.Level != "" {
+			a.Key = keys.Level
+		}
+	case slog.MessageKey:
+		if keys.Message != "" {
+			a.Key = keys.Message
+		}
+	}
+	return a
+}
+
+// applyZapKeys 设置 zap EncoderConfig 的标准字段 key 和级别写法
+func applyZapKeys(cfg *zapcore.EncoderConfig, keys FieldKeys, format LevelFormat) {
+	if keys.Time != "" {
+		cfg.TimeKey = keys.Time
+	}
+	if keys.Level != "" {
+		cfg.LevelKey = keys.Level
+	}
+	if keys.Message != "" {
+		cfg.MessageKey = keys.Message
+	}
+	if format == LevelFormatDefault {
+		return
+	}
+	cfg.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		switch v := formatLevel(FromZapLevel(l), format, "").(type) {
+		case int:
+			enc.AppendInt(v)
+		case string:
+			enc.AppendString(v)
+		}
+	}
+}
+
+// recordKeysSchema 在基于 Record 的输出结构上应用标准字段的 key 和级别写法
+func recordKeysSchema(schema *Schema, keys FieldKeys, format LevelFormat) *Schema {
+	if keys == (FieldKeys{}) && format == LevelFormatDefault {
+		return schema
+	}
+	s := *schema
+	s.levelFormat = format
+	if keys.Time != "" {
+		s.TimeKey = keys.Time
+	}
+	if keys.Level != "" {
+		s.LevelKey = keys.Level
+	}
+	if keys.Message != "" {
+		s.MessageKey = keys.Message
+	}
+	return &s
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFieldKeysJSON(t *testing.T) {
+	tests := []struct {
+		format LevelFormat
+		want   any
+	}{
+		{LevelFormatUpper, "WARN"},
+		{LevelFormatLower, "warn"},
+		{LevelFormatShort, "W"},
+		{LevelFormatNumeric, float64(WarnLevel)},
+	}
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		for _, tt := range tests {
+			for _, native := range []bool{false, true} {
+				out := &syncBuffer{}
+				opts := []Option{
+					WithFormat(FormatJSON),
+					WithConsole(ConsoleNone),
+					WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+					WithOutput(out),
+					WithFieldKeys(FieldKeys{Time: "ts", Level: "severity", Message: "message"}),
+					WithLevelFormat(tt.format)}
+				if native {
+					opts = append(opts, WithNativeEncoder())
+				}
+				logger, err := NewLoggerWithType(typ, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				logger.Warn("disk almost full", "usage", 91)
+
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(out.String()), &entry); err != nil {
+					t.Fatalf("%s: output is not json: %q", typ, out.String())
+				}
+				if entry["severity"] != tt.want || !strings.HasSuffix(fmt.Sprint(entry["message"]), "disk almost full") || entry["ts"] == nil {
+					t.Fatalf("%s: entry = %v, want severity %v", typ, entry, tt.want)
+				}
+				for _, key := range []string{"time", "level", "msg"} {
+					if _, ok := entry[key]; ok {
+						t.Fatalf("%s: default key %q still present: %v", typ, key, entry)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFieldKeysText(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		for _, native := range []bool{false, true} {
+			out := &syncBuffer{}
+			opts := []Option{
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithOutput(out),
+				WithFieldKeys(FieldKeys{Level: "severity"}),
+				WithLevelFormat(LevelFormatShort)}
+			if native {
+				opts = append(opts, WithNativeEncoder())
+			}
+			logger, err := NewLoggerWithType(typ, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Error("request failed", "status", 500)
+
+			got := out.String()
+			level := "severity=E"
+			if typ == ZapLogger && native {
+				// zap 原生的文本格式没有 key
+				level = "\tE\t"
+			}
+			if !strings.Contains(got, level) || !strings.Contains(got, "request failed") {
+				t.Fatalf("%s native=%v: output = %q", typ, native, got)
+			}
+		}
+	}
+}
+
+func TestLevelFormatDefault(t *testing.T) {
+	if got := formatLevel(InfoLevel, LevelFormatDefault, "info"); got != "info" {
+		t.Fatalf("formatLevel = %v", got)
+	}
+	if got := formatLevelString(FatalLevel, LevelFormatNumeric, ""); got != "4" {
+		t.Fatalf("formatLevelString = %q", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -18,21 +19,44 @@ type klogLogger struct {
 	colorScheme *ColorScheme
 	errorOutput string
 	maskLogger  *MaskProcessor
+	multiline   MultilinePolicy
+	name        string
+	fields      []any
+	sinks       []Sink
 }
 
 var _ Logger = (*klogLogger)(nil)
 
+var klogFlagsOnce sync.Once
+
+// initKlogFlags 将 klog 的 flag 注册到 flag.CommandLine，程序可以继续使用 -v、-vmodule、-logtostderr 等标准参数
+// flag 重复注册会 panic，创建多个 klog 日志或调用方已经调用过 klog.InitFlags(nil) 时跳过
+func initKlogFlags() {
+	klogFlagsOnce.Do(func() {
+		if flag.CommandLine.Lookup("v") == nil {
+			klog.InitFlags(flag.CommandLine)
+		}
+	})
+}
+
 func newKlogLogger(opts Options) (Logger, error) {
-	klog.InitFlags(flag.CommandLine)
+	initKlogFlags()
 	var ioWriters []io.Writer
 
 	location, err := time.LoadLocation(opts.TimeZone)
 	if err != nil {
 		return nil, err
 	}
-	if opts.FilePath != "" {
+	sinks, err := newSinks(opts)
+	if err != nil {
+		return nil, err
+	}
+	// klog 只支持文本格式，JSON 和 logfmt 由 formatOutputs 输出
+	// klog 的文本格式没有 key，设置了标准字段的 key 或级别写法时同样由 formatOutputs 输出
+	customKeys := opts.customKeys()
+	formats := newFormatOutputs(opts, func(format Format) bool { return format == FormatText && !customKeys })
+	if opts.FilePath != "" && !formats.addTargetFile(OutputFile, nil, opts.FilePath) {
 		ioWriters = append(ioWriters, getOutput(opts.FilePath))
-		klog.SetOutput(getOutput(opts.FilePath))
 	}
 	var logRotation *LogRotation
 	// 设置日志轮转
@@ -42,17 +66,48 @@ func newKlogLogger(opts Options) (Logger, error) {
 			opts.LogRotation.MaxAge,
 			opts.LogRotation.MaxBackups,
 			opts.LogRotation.Compress)
-		ioWriters = append(ioWriters, logRotation.logger)
-		multiWriter := io.MultiWriter(ioWriters...)
-		klog.SetOutput(multiWriter)
+		if !formats.addTarget(OutputRotation, nil, logRotation.logger) {
+			ioWriters = append(ioWriters, logRotation.logger)
+		}
+	}
+	if len(opts.outputs) > 0 {
+		outputs := io.MultiWriter(opts.outputs...)
+		if !formats.add(opts.Format, nil, outputs) {
+			ioWriters = append(ioWriters, outputs)
+		}
+	}
+	// 控制台与文件使用相同的 klog 输出，文本格式下无法单独设置颜色
+	if console := consoleOutput(opts.Console, nil); console != nil {
+		if !formats.addTarget(OutputConsole, nil, console) {
+			ioWriters = append(ioWriters, console)
+		}
+	}
+	if len(ioWriters) > 0 {
+		klog.SetOutput(io.MultiWriter(ioWriters...))
+	} else if len(formats.sinks) > 0 {
+		// 所有输出都由 formatOutputs 处理时，klog 不再输出
+		klog.SetOutput(io.Discard)
 	}
 	klog.LogToStderr(false)
-	if opts.ErrorOutput != "" {
+	// klog 默认会把 ERROR 日志额外输出到 stderr，显式设置控制台或所有输出都由本库编码时关闭
+	stderrThreshold := "ERROR"
+	if opts.Console != ConsoleDefault || (len(ioWriters) == 0 && len(formats.sinks) > 0) {
+		stderrThreshold = "FATAL"
+	}
+	if err := flag.CommandLine.Set("stderrthreshold", stderrThreshold); err != nil {
+		return nil, err
+	}
+	// 错误日志文件使用 JSON 或 logfmt 时，ERROR 级别的日志仍然保留在 klog 的输出中
+	if opts.ErrorOutput != "" && !formats.addTargetFile(OutputErrorFile, MatchMinLevel(ErrorLevel), opts.ErrorOutput) {
+		errorOutput := getOutput(opts.ErrorOutput)
 		if logRotation != nil {
-			multiWriter := io.MultiWriter(getOutput(opts.ErrorOutput), logRotation.logger)
-			klog.SetOutputBySeverity("ERROR", multiWriter)
+			errorOutput = io.MultiWriter(errorOutput, logRotation.logger)
 		}
-		klog.SetOutputBySeverity("ERROR", getOutput(opts.ErrorOutput))
+		klog.SetOutputBySeverity("ERROR", errorOutput)
+	}
+	if formats.err != nil {
+		closeSinks(append(sinks, formats.sinks...))
+		return nil, formats.err
 	}
 	if err := flag.CommandLine.Set("one_output", "true"); err != nil {
 		return nil, err
@@ -68,30 +123,53 @@ func newKlogLogger(opts Options) (Logger, error) {
 		errorOutput: opts.ErrorOutput,
 		colorScheme: opts.ColorScheme,
 		timeZone:    location,
+		name:        opts.Name,
+		sinks:       append(sinks, formats.sinks...),
 	}
 	if opts.MaskEnable {
-		klogLogger.maskLogger = NewMaskProcessor(opts.maskRules...)
+		klogLogger.maskLogger = newMaskProcessor(opts)
 	}
+	klogLogger.multiline = opts.multiline
 	return klogLogger, nil
 }
 
+// log 按 MultilinePolicy 处理日志内容后写入，拆分后的每条日志单独写入
 func (l *klogLogger) log(level Level, msg string, args ...any) {
-	defer klog.Flush()
-	if l.level > level {
+	if l.multiline == MultilineKeep {
+		l.write(level, msg, args...)
 		return
 	}
+	for _, part := range applyMultiline(l.multiline, msg, args) {
+		l.write(level, part.msg, part.args...)
+	}
+}
 
-	// 追加时间戳到日志消息
-	timestamp := time.Now().In(l.timeZone).Format(time.DateTime)
-	msg = fmt.Sprintf("[%s] %s", timestamp, msg)
+func (l *klogLogger) write(level Level, msg string, args ...any) {
+	defer klog.Flush()
+	enabled := level >= l.level
+	sinkEnabled := sinksEnabled(l.sinks, level, enabled)
+	if !enabled && !sinkEnabled {
+		return
+	}
+	if l.maskLogger != nil {
+		args = l.maskLogger.Process(args...)
+	}
+	if sinkEnabled {
+		writeSinks(l.sinks, enabled, newRecord(l.name, level, msg, l.addSource, 4, l.fields, args))
+	}
+	if !enabled {
+		return
+	}
 
+	// klog 的日志头已经包含时间，日志内容与 Record 一致，不再拼接时间
 	if l.colorScheme != nil {
 		msg = l.colorScheme.Colorize(level, msg)
 	}
-	if l.maskLogger != nil {
-		args = l.maskLogger.Process(args...)
+	kvs := make([]any, 0, len(l.fields)+len(args)+2)
+	if l.name != "" {
+		kvs = append(kvs, defaultSchema.LoggerKey, l.name)
 	}
-	kvs := make([]any, 0, len(args)*2)
+	kvs = append(kvs, l.fields...)
 	for i := 0; i < len(args); i += 2 {
 		if i+1 >= len(args) {
 			break
@@ -105,28 +183,28 @@ func (l *klogLogger) log(level Level, msg string, args ...any) {
 
 	switch level {
 	case DebugLevel:
-		klog.V(5).InfoSDepth(2, msg, kvs...)
+		klog.V(5).InfoSDepth(3, msg, kvs...)
 	case InfoLevel:
 		if l.addSource {
-			klog.InfoSDepth(2, msg, kvs...)
+			klog.InfoSDepth(3, msg, kvs...)
 			break
 		}
 		klog.InfoS(msg, kvs...)
 	case WarnLevel:
 		if l.addSource {
-			klog.WarningfDepth(2, "%s %v", msg, kvs) // Warningf 只能格式化
+			klog.WarningfDepth(3, "%s %v", msg, kvs) // Warningf 只能格式化
 			break
 		}
 		klog.Warningf("%s %v", msg, kvs) // Warningf 只能格式化
 	case ErrorLevel:
 		if l.addSource {
-			klog.ErrorfDepth(2, "%s %v", msg, kvs)
+			klog.ErrorfDepth(3, "%s %v", msg, kvs)
 			break
 		}
 		klog.ErrorS(nil, msg, kvs...)
 	default:
 		if l.addSource {
-			klog.InfoSDepth(2, msg, kvs...)
+			klog.InfoSDepth(3, msg, kvs...)
 			break
 		}
 		klog.InfoS(msg, kvs...)
@@ -181,12 +259,25 @@ func (l *klogLogger) SetLevel(level Level) {
 }
 
 func (l *klogLogger) WithFields(fields map[string]any) Logger {
-	// klog不支持结构化日志，返回新实例但保留字段
+	// 持久化字段与单次调用参数走同一条脱敏链路
+	if l.maskLogger != nil {
+		fields = l.maskLogger.ProcessFields(fields)
+	}
+	fields = multilineFields(l.multiline, fields)
+	newFields := make([]any, 0, len(l.fields)+len(fields)*2)
+	newFields = append(newFields, l.fields...)
+	newFields = append(newFields, fieldsToArgs(fields)...)
 	return &klogLogger{
 		level:       l.level,
 		filePath:    l.filePath,
+		timeZone:    l.timeZone,
 		addSource:   l.addSource,
 		colorScheme: l.colorScheme,
 		errorOutput: l.errorOutput,
+		maskLogger:  l.maskLogger,
+		multiline:   l.multiline,
+		name:        l.name,
+		fields:      newFields,
+		sinks:       l.sinks,
 	}
 }
@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/klog/v2"
 )
 
 func TestKlog(t *testing.T) {
-	klog.InitFlags(nil)
+	initKlogFlags()
 	// By default klog writes to stderr. Setting logtostderr to false makes klog
 	// write to a log file.
 	if err := flag.Set("logtostderr", "false"); err != nil {
@@ -25,7 +26,7 @@ func TestKlog(t *testing.T) {
 }
 
 func TestKlogSetOutput(t *testing.T) {
-	klog.InitFlags(nil)
+	initKlogFlags()
 	if err := flag.Set("logtostderr", "false"); err != nil {
 		t.Fatal(err)
 	}
@@ -40,3 +41,30 @@ func TestKlogSetOutput(t *testing.T) {
 
 	fmt.Printf("LOGGED: %s", buf.String())
 }
+
+func TestKlogCommandLineFlags(t *testing.T) {
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(KlogLogger, WithLevel(DebugLevel), WithConsole(ConsoleNone), WithOutput(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 程序可以通过 -v 等标准参数控制 klog
+	for _, name := range []string{"v", "vmodule", "logtostderr"} {
+		if flag.CommandLine.Lookup(name) == nil {
+			t.Fatalf("klog flag -%s is not registered on flag.CommandLine", name)
+		}
+	}
+	v := flag.CommandLine.Lookup("v").Value.String()
+	if err := flag.CommandLine.Set("v", "5"); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.CommandLine.Set("v", v)
+	logger.Debug("verbose enabled")
+	if !strings.Contains(out.String(), "verbose enabled") {
+		t.Fatalf("debug output = %q", out.String())
+	}
+	// 再次创建不会重复注册 flag
+	if _, err := NewLoggerWithType(KlogLogger, WithConsole(ConsoleNone), WithOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,8 +1,16 @@
 package logger
 
 import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 // MaskHandler 脱敏处理器接口
@@ -14,17 +22,59 @@ type MaskHandler interface {
 	Mask(fieldName string, value any) any
 }
 
+// MaskReporter 可选接口，MaskHandler 同时实现该接口时 MaskProcessor 调用 MaskReport 代替 Mask，
+// 由处理器报告是否实际进行了脱敏，用于审计统计、dry-run 和判断是否需要复制参数
+// 未实现时通过比较前后的值判断，map、切片等不可比较的值无法判断，视为未脱敏
+type MaskReporter interface {
+	// MaskReport 与 Mask 相同，另外返回是否实际进行了脱敏；未脱敏时应原样返回 value 和 false
+	MaskReport(fieldName string, value any) (masked any, ok bool)
+}
+
 // MaskProcessor 脱敏处理器
 type MaskProcessor struct {
 	maskHandlers []MaskHandler
 	mu           sync.RWMutex
+
+	// 审计统计：每个处理器在每个字段上实际脱敏的次数
+	statsMu sync.Mutex
+	stats   map[maskStatKey]uint64
+
+	// dry-run 模式下只记录将被脱敏的字段，不修改输出
+	dryRunMu     sync.Mutex
+	dryRunOutput io.Writer
+}
+
+type maskStatKey struct {
+	handler string
+	field   string
+}
+
+// MaskStat 单个脱敏处理器在单个字段上的脱敏次数
+type MaskStat struct {
+	Handler string // 处理器类型，例如 *logger.PasswordMark
+	Field   string // 字段名
+	Count   uint64 // 脱敏次数，dry-run 模式下为将被脱敏的次数
 }
 
 // NewMaskProcessor 创建新的脱敏处理器
 func NewMaskProcessor(handlers ...MaskHandler) *MaskProcessor {
 	return &MaskProcessor{
 		maskHandlers: handlers,
+		stats:        make(map[maskStatKey]uint64),
+	}
+}
+
+// newMaskProcessor 根据配置创建日志实例使用的脱敏处理器
+// 通过 WithMaskProcessor 指定时直接复用，便于调用方读取审计统计
+func newMaskProcessor(opts Options) *MaskProcessor {
+	p := opts.maskProcessor
+	if p == nil {
+		p = NewMaskProcessor(opts.maskRules...)
+	}
+	if opts.maskDryRunOutput != nil {
+		p.SetDryRun(opts.maskDryRunOutput)
 	}
+	return p
 }
 
 // RegisterHandler 注册脱敏处理器
@@ -34,19 +84,222 @@ func (p *MaskProcessor) RegisterHandler(handler ...MaskHandler) {
 	p.maskHandlers = append(p.maskHandlers, handler...)
 }
 
+// SetDryRun 设置 dry-run 模式
+// w 不为 nil 时，脱敏处理器不再修改输出，只把将被脱敏的字段写入 w；传入 nil 关闭 dry-run
+func (p *MaskProcessor) SetDryRun(w io.Writer) {
+	p.dryRunMu.Lock()
+	defer p.dryRunMu.Unlock()
+	p.dryRunOutput = w
+}
+
+// Stats 返回脱敏审计统计，按处理器和字段名排序
+func (p *MaskProcessor) Stats() []MaskStat {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	stats := make([]MaskStat, 0, len(p.stats))
+	for key, count := range p.stats {
+		stats = append(stats, MaskStat{Handler: key.handler, Field: key.field, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Handler != stats[j].Handler {
+			return stats[i].Handler < stats[j].Handler
+		}
+		return stats[i].Field < stats[j].Field
+	})
+	return stats
+}
+
+// ResetStats 清空脱敏审计统计
+func (p *MaskProcessor) ResetStats() {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	p.stats = make(map[maskStatKey]uint64)
+}
+
 // Process 执行脱敏处理
+// args 为 key/value 交替的参数列表，只有 string 类型的 key 会参与脱敏，其余 key 对应的值原样保留；
+// 奇数个参数时最后一个没有值的 key 原样保留。
+// Process 不会修改传入的 args：没有值被脱敏时直接返回 args，否则返回一份新的切片，
+// 因此并发调用方可以安全地复用同一个参数切片
 func (p *MaskProcessor) Process(args ...any) []any {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	for i := 0; i < len(args); i += 2 {
-		if key, ok := args[i].(string); ok {
-			for _, masker := range p.maskHandlers {
-				args[i+1] = masker.Mask(key, args[i+1])
+	result := args
+	copied := false
+	for i := 0; i+1 < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			continue
+		}
+		masked, ok := p.maskValue(key, args[i+1])
+		if !ok {
+			continue
+		}
+		if !copied {
+			result = make([]any, len(args))
+			copy(result, args)
+			copied = true
+		}
+		result[i+1] = masked
+	}
+	return result
+}
+
+// ProcessFields 对持久化字段执行脱敏处理，返回新的字段集合，不修改传入的 fields
+func (p *MaskProcessor) ProcessFields(fields map[string]any) map[string]any {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	masked := make(map[string]any, len(fields))
+	for key, value := range fields {
+		masked[key], _ = p.maskValue(key, value)
+	}
+	return masked
+}
+
+// maskValue 依次执行所有脱敏处理器并记录审计统计，返回脱敏后的值以及值是否被修改，调用方需持有读锁
+// dry-run 模式下返回原始值和 false
+func (p *MaskProcessor) maskValue(key string, value any) (any, bool) {
+	masked := value
+	var fired []string
+	for _, masker := range p.maskHandlers {
+		result, ok := maskReport(masker, key, masked)
+		if !ok {
+			continue
+		}
+		masked = result
+		handler := fmt.Sprintf("%T", masker)
+		p.record(handler, key)
+		fired = append(fired, handler)
+	}
+	if len(fired) == 0 {
+		return value, false
+	}
+
+	p.dryRunMu.Lock()
+	defer p.dryRunMu.Unlock()
+	if p.dryRunOutput == nil {
+		return masked, true
+	}
+	_, _ = fmt.Fprintf(p.dryRunOutput, "time=%s msg=%q field=%q handlers=%q\n",
+		time.Now().Format(time.RFC3339), "mask dry-run", key, strings.Join(fired, ","))
+	return value, false
+}
+
+// maskReport 执行单个脱敏处理器，返回脱敏后的值以及是否实际进行了脱敏
+func maskReport(masker MaskHandler, key string, value any) (any, bool) {
+	if reporter, ok := masker.(MaskReporter); ok {
+		return reporter.MaskReport(key, value)
+	}
+	result := masker.Mask(key, value)
+	return result, maskChanged(value, result)
+}
+
+// maskChanged 比较未实现 MaskReporter 的处理器脱敏前后的值
+// 类型不同视为已脱敏；同类型的值不可比较（map、切片、func 等）时无法判断，视为未脱敏；NaN 视为与自身相等
+func maskChanged(before, after any) bool {
+	vb, va := reflect.ValueOf(before), reflect.ValueOf(after)
+	if !vb.IsValid() || !va.IsValid() {
+		return vb.IsValid() != va.IsValid()
+	}
+	if vb.Type() != va.Type() {
+		return true
+	}
+	if !vb.Comparable() || !va.Comparable() || vb.Equal(va) {
+		return false
+	}
+	if k := vb.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+		return !math.IsNaN(vb.Float()) || !math.IsNaN(va.Float())
+	}
+	return true
+}
+
+func (p *MaskProcessor) record(handler, field string) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	if p.stats == nil {
+		p.stats = make(map[maskStatKey]uint64)
+	}
+	p.stats[maskStatKey{handler: handler, field: field}]++
+}
+
+// 默认的字段别名，匹配时忽略大小写以及 snake/camel/kebab 命名差异
+var (
+	// DefaultPasswordAliases 密码字段默认别名
+	DefaultPasswordAliases = []string{"password", "pwd", "passwd"}
+	// DefaultPhoneAliases 手机号字段默认别名
+	DefaultPhoneAliases = []string{"phone", "mobile"}
+)
+
+// NormalizeFieldName 将字段名规范化为小写 snake_case 形式
+// 例如 Password -> password，mobilePhone -> mobile_phone，x-api-key -> x_api_key，APIKey -> api_key
+func NormalizeFieldName(name string) string {
+	return strings.Join(splitFieldName(name), "_")
+}
+
+// MatchFieldName 判断字段名是否命中任一别名
+// 字段名和别名都会先规范化，别名的各个词在字段名中连续出现即视为命中，
+// 例如别名 password 可以命中 Password、user_password、userPassword，别名 api_key 可以命中 x-api-key
+func MatchFieldName(fieldName string, aliases ...string) bool {
+	words := splitFieldName(fieldName)
+	for _, alias := range aliases {
+		if containsWords(words, splitFieldName(alias)) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFieldName 按分隔符和驼峰边界将字段名拆分为小写单词
+func splitFieldName(name string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// userPassword 在 P 处切分；APIKey 在 K 处切分
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, unicode.ToLower(r))
+	}
+	flush()
+	return words
+}
+
+// containsWords 判断 sub 是否作为连续片段出现在 words 中
+func containsWords(words, sub []string) bool {
+	if len(sub) == 0 || len(sub) > len(words) {
+		return false
+	}
+	for i := 0; i+len(sub) <= len(words); i++ {
+		matched := true
+		for j := range sub {
+			if words[i+j] != sub[j] {
+				matched = false
+				break
 			}
 		}
+		if matched {
+			return true
+		}
 	}
-	return args
+	return false
 }
 
 /*
@@ -56,23 +309,51 @@ func (p *MaskProcessor) Process(args ...any) []any {
  */
 
 // PasswordMark 脱敏处理器，用于标记密码字段
-type PasswordMark struct{}
+type PasswordMark struct {
+	// Aliases 需要脱敏的字段别名，为空时使用 DefaultPasswordAliases
+	Aliases []string
+}
 
 func (p *PasswordMark) Mask(fieldName string, value any) any {
-	if fieldName == "password" || fieldName == "pwd" {
-		return "[****]"
+	masked, _ := p.MaskReport(fieldName, value)
+	return masked
+}
+
+// MaskReport 实现 MaskReporter
+func (p *PasswordMark) MaskReport(fieldName string, value any) (any, bool) {
+	aliases := p.Aliases
+	if len(aliases) == 0 {
+		aliases = DefaultPasswordAliases
 	}
-	return value
+	if MatchFieldName(fieldName, aliases...) {
+		return "[****]", true
+	}
+	return value, false
 }
 
+var phoneRegexp = regexp.MustCompile(`(\d{3})\d{4}(\d{4})`)
+
 // PhoneMask 脱敏处理器，用于隐藏手机号中间四位
-type PhoneMask struct{}
+type PhoneMask struct {
+	// Aliases 需要脱敏的字段别名，为空时使用 DefaultPhoneAliases
+	Aliases []string
+}
 
 func (m *PhoneMask) Mask(fieldName string, value any) any {
-	if fieldName == "phone" {
-		if s, ok := value.(string); ok {
-			return regexp.MustCompile(`(\d{3})\d{4}(\d{4})`).ReplaceAllString(s, "$1****$2")
+	masked, _ := m.MaskReport(fieldName, value)
+	return masked
+}
+
+// MaskReport 实现 MaskReporter
+func (m *PhoneMask) MaskReport(fieldName string, value any) (any, bool) {
+	aliases := m.Aliases
+	if len(aliases) == 0 {
+		aliases = DefaultPhoneAliases
+	}
+	if MatchFieldName(fieldName, aliases...) {
+		if s, ok := value.(string); ok && phoneRegexp.MatchString(s) {
+			return phoneRegexp.ReplaceAllString(s, "$1****$2"), true
 		}
 	}
-	return value
+	return value, false
 }
@@ -0,0 +1,347 @@
+package logger
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMaskProcessorProcessFields(t *testing.T) {
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	fields := map[string]any{"password": "secret", "phone": "13812345678", "user": "alice"}
+	masked := p.ProcessFields(fields)
+	if masked["password"] != "[****]" {
+		t.Fatalf("password not masked: %v", masked["password"])
+	}
+	if masked["phone"] != "138****5678" {
+		t.Fatalf("phone not masked: %v", masked["phone"])
+	}
+	if masked["user"] != "alice" {
+		t.Fatalf("user should be kept: %v", masked["user"])
+	}
+	if fields["password"] != "secret" {
+		t.Fatalf("input fields should not be modified: %v", fields["password"])
+	}
+}
+
+func TestWithFieldsMask(t *testing.T) {
+	for _, loggerType := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(loggerType), func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "mask.log")
+			logger, err := NewLoggerWithType(loggerType, WithFileOutput(filePath), WithMark())
+			if err != nil {
+				t.Fatal(err)
+			}
+			fieldLogger := logger.WithFields(map[string]any{"password": "secret-token", "user": "alice"})
+			fieldLogger.Info("first")
+			fieldLogger.WithFields(map[string]any{"phone": "13812345678"}).Info("second")
+
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := string(data)
+			if strings.Contains(content, "secret-token") || strings.Contains(content, "13812345678") {
+				t.Fatalf("persistent fields not masked:\n%s", content)
+			}
+			if strings.Count(content, "alice") != 2 {
+				t.Fatalf("persistent fields should be kept on every record:\n%s", content)
+			}
+			if !strings.Contains(content, "138****5678") {
+				t.Fatalf("nested fields not masked:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestNormalizeFieldName(t *testing.T) {
+	cases := map[string]string{
+		"Password":      "password",
+		"user_password": "user_password",
+		"x-api-key":     "x_api_key",
+		"mobilePhone":   "mobile_phone",
+		"APIKey":        "api_key",
+		"User.Phone2":   "user_phone2",
+	}
+	for in, want := range cases {
+		if got := NormalizeFieldName(in); got != want {
+			t.Errorf("NormalizeFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatchFieldName(t *testing.T) {
+	cases := []struct {
+		field   string
+		aliases []string
+		want    bool
+	}{
+		{"Password", DefaultPasswordAliases, true},
+		{"user_password", DefaultPasswordAliases, true},
+		{"PWD", DefaultPasswordAliases, true},
+		{"mobilePhone", DefaultPhoneAliases, true},
+		{"x-api-key", []string{"api_key"}, true},
+		{"XApiKey", []string{"api-key"}, true},
+		{"passwordless", DefaultPasswordAliases, false},
+		{"api", []string{"api_key"}, false},
+		{"username", DefaultPasswordAliases, false},
+	}
+	for _, c := range cases {
+		if got := MatchFieldName(c.field, c.aliases...); got != c.want {
+			t.Errorf("MatchFieldName(%q, %v) = %v, want %v", c.field, c.aliases, got, c.want)
+		}
+	}
+}
+
+func TestMaskAliases(t *testing.T) {
+	p := NewMaskProcessor(&PasswordMark{Aliases: []string{"token", "api_key"}}, &PhoneMask{})
+	args := p.Process("x-api-key", "abc", "AccessToken", "def", "Password", "ghi", "mobilePhone", "13812345678")
+	if args[1] != "[****]" || args[3] != "[****]" {
+		t.Fatalf("custom aliases not masked: %v", args)
+	}
+	if args[5] != "ghi" {
+		t.Fatalf("custom aliases should replace defaults: %v", args)
+	}
+	if args[7] != "138****5678" {
+		t.Fatalf("phone alias not masked: %v", args)
+	}
+}
+
+func TestHMACTokenMask(t *testing.T) {
+	mask, err := NewHMACTokenMask([]byte("local-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, ok := mask.MaskReport("phone", "13812345678")
+	if !ok {
+		t.Fatal("matched field should be reported as masked")
+	}
+	second, _ := mask.MaskReport("mobilePhone", "13812345678")
+	other, _ := mask.MaskReport("phone", "13900000000")
+	if first != second {
+		t.Fatalf("same input should produce same token: %v != %v", first, second)
+	}
+	if first == other {
+		t.Fatalf("different input should produce different token: %v", first)
+	}
+	if !strings.HasPrefix(first.(string), "hmac:") {
+		t.Fatalf("unexpected token: %v", first)
+	}
+	if _, err := mask.Reveal(first.(string)); err == nil {
+		t.Fatal("hmac token should not be reversible")
+	}
+	if value, ok := mask.MaskReport("user", "alice"); ok || value != "alice" {
+		t.Fatal("unmatched field should be kept")
+	}
+}
+
+func TestEncryptTokenMask(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	mask, err := NewEncryptTokenMask(key, "phone", "id_card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked, _ := mask.MaskReport("idCard", "110101199001011234")
+	token := masked.(string)
+	if again, _ := mask.MaskReport("id_card", "110101199001011234"); token != again {
+		t.Fatal("same input should produce same ciphertext")
+	}
+	plain, err := RevealToken(key, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "110101199001011234" {
+		t.Fatalf("reveal = %q", plain)
+	}
+	if _, err := RevealToken([]byte("fedcba9876543210fedcba9876543210"), token); err == nil {
+		t.Fatal("reveal with wrong key should fail")
+	}
+	if _, err := mask.Reveal("enc:!!!"); err == nil {
+		t.Fatal("reveal malformed token should fail")
+	}
+	if _, err := NewEncryptTokenMask([]byte("short")); err == nil {
+		t.Fatal("invalid key size should fail")
+	}
+}
+
+func TestMaskProcessorStats(t *testing.T) {
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	p.Process("password", "a", "user", "alice")
+	p.Process("Password", "b", "phone", "13812345678")
+	p.ProcessFields(map[string]any{"password": "c"})
+
+	want := []MaskStat{
+		{Handler: "*logger.PasswordMark", Field: "Password", Count: 1},
+		{Handler: "*logger.PasswordMark", Field: "password", Count: 2},
+		{Handler: "*logger.PhoneMask", Field: "phone", Count: 1},
+	}
+	got := p.Stats()
+	if len(got) != len(want) {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("stats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	p.ResetStats()
+	if len(p.Stats()) != 0 {
+		t.Fatalf("stats should be empty after reset: %+v", p.Stats())
+	}
+}
+
+func TestMaskProcessorStatsUnchangedValues(t *testing.T) {
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	var dryRun strings.Builder
+	p.SetDryRun(&dryRun)
+	// NaN 和 func 与自身不相等，未被修改时不应计入统计
+	p.Process("phone", math.NaN(), "callback", func() {}, "mobile", 13812345678)
+	if stats := p.Stats(); len(stats) != 0 {
+		t.Fatalf("unchanged values should not be counted: %+v", stats)
+	}
+	if dryRun.Len() != 0 {
+		t.Fatalf("unchanged values should not be reported:\n%s", dryRun.String())
+	}
+}
+
+// TestMaskProcessorStatsLegacyHandler 未实现 MaskReporter 的处理器通过比较前后的值统计
+func TestMaskProcessorStatsLegacyHandler(t *testing.T) {
+	p := NewMaskProcessor(&AddressMask{})
+	args := []any{"address", math.NaN(), "address", func() {}, "address", map[string]any{"city": "x"}}
+	if got := p.Process(args...); &got[0] != &args[0] {
+		t.Fatal("unchanged values should not copy args")
+	}
+	if got := p.Process("address", "Beijing Road"); got[1] != "Beij****" {
+		t.Fatalf("masked = %v", got[1])
+	}
+	if stats := p.Stats(); len(stats) != 1 || stats[0].Handler != "*logger.AddressMask" || stats[0].Count != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestMaskDryRun(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "app.log")
+	var dryRun strings.Builder
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	logger, err := NewLoggerWithType(SlogLogger, WithFileOutput(filePath), WithMaskProcessor(p), WithMaskDryRun(&dryRun))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("login", "password", "secret", "user", "alice")
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "password=secret") {
+		t.Fatalf("dry-run should not alter output:\n%s", data)
+	}
+	if !strings.Contains(dryRun.String(), `field="password"`) || strings.Contains(dryRun.String(), "user") {
+		t.Fatalf("unexpected dry-run report:\n%s", dryRun.String())
+	}
+	if stats := p.Stats(); len(stats) != 1 || stats[0].Count != 1 {
+		t.Fatalf("dry-run should still be counted: %+v", stats)
+	}
+}
+
+func TestMaskProcessorProcessNotMutating(t *testing.T) {
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	args := []any{"password", "secret", 42, "password", "phone"}
+	masked := p.Process(args...)
+	if args[1] != "secret" {
+		t.Fatalf("input args should not be modified: %v", args)
+	}
+	if masked[1] != "[****]" {
+		t.Fatalf("password not masked: %v", masked)
+	}
+	if masked[3] != "password" {
+		t.Fatalf("value of non-string key should be kept: %v", masked)
+	}
+	if len(masked) != len(args) || masked[4] != "phone" {
+		t.Fatalf("dangling key should be kept: %v", masked)
+	}
+
+	unchanged := []any{"user", "alice"}
+	if got := p.Process(unchanged...); &got[0] != &unchanged[0] {
+		t.Fatal("args without masked values should not be copied")
+	}
+	// NaN 和 func 与自身不相等，同样不应复制
+	uncomparable := []any{"phone", math.NaN(), "callback", func() {}}
+	if got := p.Process(uncomparable...); &got[0] != &uncomparable[0] {
+		t.Fatal("args with NaN or func values should not be copied")
+	}
+}
+
+func TestMaskProcessorConcurrent(t *testing.T) {
+	p := NewMaskProcessor(DefaultMaskHandler()...)
+	args := []any{"password", "secret", "phone", "13812345678"}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				masked := p.Process(args...)
+				if masked[1] != "[****]" || masked[3] != "138****5678" {
+					t.Errorf("unexpected masked args: %v", masked)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if args[1] != "secret" || args[3] != "13812345678" {
+		t.Fatalf("shared args should not be modified: %v", args)
+	}
+}
+
+func TestZapLoggerMaskOddArgs(t *testing.T) {
+	logger, err := NewLoggerWithType(ZapLogger, WithFileOutput(filepath.Join(t.TempDir(), "zap.log")), WithMark())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("odd args", "password", "secret", "dangling")
+}
+
+func FuzzMaskProcessorProcess(f *testing.F) {
+	f.Add("password", "secret", "phone", "13812345678", true, false)
+	f.Add("user", "alice", "mobilePhone", "", false, true)
+	f.Add("", "", "x-api-key", "abc", true, true)
+	p := NewMaskProcessor(&PasswordMark{}, &PhoneMask{}, &PasswordMark{Aliases: []string{"api_key"}})
+	f.Fuzz(func(t *testing.T, k1, v1, k2, v2 string, odd, nonStringKey bool) {
+		args := []any{k1, v1, k2, v2}
+		if nonStringKey {
+			args[2] = len(k2)
+		}
+		if odd {
+			args = append(args, k1)
+		}
+		original := append([]any(nil), args...)
+
+		masked := p.Process(args...)
+		if len(masked) != len(args) {
+			t.Fatalf("len(masked) = %d, want %d", len(masked), len(args))
+		}
+		for i := range args {
+			if args[i] != original[i] {
+				t.Fatalf("input args modified at %d: %v -> %v", i, original[i], args[i])
+			}
+			// key 以及无法脱敏的值必须原样保留
+			if i%2 == 0 || i == len(args)-1 && odd {
+				if masked[i] != args[i] {
+					t.Fatalf("key modified at %d: %v -> %v", i, args[i], masked[i])
+				}
+			}
+		}
+		if nonStringKey && masked[3] != args[3] {
+			t.Fatalf("value of non-string key modified: %v -> %v", args[3], masked[3])
+		}
+		if MatchFieldName(k1, DefaultPasswordAliases...) && masked[1] != "[****]" {
+			t.Fatalf("password field %q not masked: %v", k1, masked[1])
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 令牌前缀，用于区分令牌类型
+const (
+	hmacTokenPrefix    = "hmac:"
+	encryptTokenPrefix = "enc:"
+)
+
+// TokenMode 令牌化脱敏模式
+type TokenMode int
+
+const (
+	// TokenModeHMAC 使用 HMAC-SHA256 生成确定性令牌，不可逆
+	TokenModeHMAC TokenMode = iota
+	// TokenModeEncrypt 使用 AES-GCM 加密，持有密钥可还原
+	TokenModeEncrypt
+)
+
+// ErrInvalidToken 令牌格式错误或无法解密
+var ErrInvalidToken = errors.New("invalid mask token")
+
+// TokenMask 令牌化脱敏处理器
+// 相同的输入总是得到相同的令牌，便于在不暴露原始值的情况下关联同一个用户的多条日志
+type TokenMask struct {
+	// Aliases 需要令牌化的字段别名，为空时使用 DefaultPhoneAliases
+	Aliases  []string
+	mode     TokenMode
+	hmacKey  []byte
+	nonceKey []byte
+	aead     cipher.AEAD
+}
+
+// NewHMACTokenMask 创建 HMAC 令牌化脱敏处理器
+// 令牌格式为 hmac:<hex>，不可还原，只能用于关联
+func NewHMACTokenMask(key []byte, aliases ...string) (*TokenMask, error) {
+	if len(key) == 0 {
+		return nil, errors.New("hmac token key is empty")
+	}
+	return &TokenMask{
+		Aliases: aliases,
+		mode:    TokenModeHMAC,
+		hmacKey: key,
+	}, nil
+}
+
+// NewEncryptTokenMask 创建 AES-GCM 加密脱敏处理器
+// key 长度必须为 16、24 或 32 字节，分别对应 AES-128、AES-192、AES-256
+// 令牌格式为 enc:<base64url>，持有密钥的授权人员可通过 RevealToken 还原
+func NewEncryptTokenMask(key []byte, aliases ...string) (*TokenMask, error) {
+	aead, err := newTokenAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenMask{
+		Aliases:  aliases,
+		mode:     TokenModeEncrypt,
+		nonceKey: deriveNonceKey(key),
+		aead:     aead,
+	}, nil
+}
+
+func (m *TokenMask) Mask(fieldName string, value any) any {
+	masked, _ := m.MaskReport(fieldName, value)
+	return masked
+}
+
+// MaskReport 实现 MaskReporter
+func (m *TokenMask) MaskReport(fieldName string, value any) (any, bool) {
+	aliases := m.Aliases
+	if len(aliases) == 0 {
+		aliases = DefaultPhoneAliases
+	}
+	if !MatchFieldName(fieldName, aliases...) || value == nil {
+		return value, false
+	}
+	plaintext := []byte(fmt.Sprint(value))
+	switch m.mode {
+	case TokenModeEncrypt:
+		// nonce 由明文派生，保证相同输入得到相同密文
+		nonce := hmacSum(m.nonceKey, plaintext)[:m.aead.NonceSize()]
+		sealed := m.aead.Seal(nonce, nonce, plaintext, nil)
+		return encryptTokenPrefix + base64.RawURLEncoding.EncodeToString(sealed), true
+	default:
+		return hmacTokenPrefix + hex.EncodeToString(hmacSum(m.hmacKey, plaintext)[:16]), true
+	}
+}
+
+// Reveal 还原 TokenModeEncrypt 模式生成的令牌
+func (m *TokenMask) Reveal(token string) (string, error) {
+	if m.mode != TokenModeEncrypt {
+		return "", fmt.Errorf("%w: hmac tokens are not reversible", ErrInvalidToken)
+	}
+	return revealToken(m.aead, token)
+}
+
+// RevealToken 使用本地密钥还原加密令牌，供授权人员排查问题时使用
+func RevealToken(key []byte, token string) (string, error) {
+	aead, err := newTokenAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	return revealToken(aead, token)
+}
+
+func revealToken(aead cipher.AEAD, token string) (string, error) {
+	encoded, ok := strings.CutPrefix(token, encryptTokenPrefix)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidToken
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return string(plaintext), nil
+}
+
+func newTokenAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveNonceKey 从加密密钥派生独立的 nonce 密钥，避免同一密钥用于两种用途
+func deriveNonceKey(key []byte) []byte {
+	return hmacSum(key, []byte("go-logger/token-nonce"))
+}
+
+func hmacSum(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
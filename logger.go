@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -10,7 +11,7 @@ type Level int
 
 const (
 	defaultLevel       = InfoLevel         // 默认日志级别 / Default log level
-	defaultJSONFormat  = false             // 默认非 JSON 格式 / Default is non-JSON format
+	defaultFormat      = FormatText        // 默认文本格式 / Default is text format
 	defaultAddSource   = false             // 默认不打印调用信息 / Default: no caller info
 	defaultLogFile     = "./app.log"       // 默认日志文件路径 / Default log file path
 	defaultTimeFormat  = time.DateTime     // 默认时间格式 / Default time format
@@ -26,6 +27,24 @@ const (
 	FatalLevel              // 致命错误级别 / Fatal level
 )
 
+// String 返回日志级别的大写名称
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
 // 常见时区定义 / Common timezone constants
 const (
 	// 亚洲时区 / Asia timezones
@@ -146,9 +165,9 @@ type Options struct {
 	// Logging level
 	// 设置日志级别
 	Level Level
-	// Use JSON format
-	// 以JSON格式输出日志
-	JSONFormat bool
+	// Output format
+	// 日志输出格式，默认文本格式
+	Format Format
 	// Log file path
 	// 设置日志文件路径
 	FilePath string
@@ -174,9 +193,32 @@ type Options struct {
 	// Custom color scheme
 	// 主题颜色方案
 	ColorScheme *ColorScheme
-	//
-	MaskEnable bool
-	maskRules  []MaskHandler
+	// Console output target
+	// 控制台输出目标
+	Console       ConsoleTarget
+	outputFormats map[OutputTarget]Format
+	ecsNamespace  string
+	schema        *Schema
+	nativeEncoder bool
+	fieldKeys     FieldKeys
+	securityEvent SecurityEventConfig
+	encoder       Encoder
+	multiline     MultilinePolicy
+	levelFormat   LevelFormat
+	outputColors  map[OutputTarget]bool
+	// Logger name
+	// 日志名称，用于路由匹配以及 Sink 输出
+	Name string
+	// Enable masking
+	// 是否开启脱敏
+	MaskEnable       bool
+	maskRules        []MaskHandler
+	maskProcessor    *MaskProcessor
+	maskDryRunOutput io.Writer
+	// 额外的日志输出
+	outputs []io.Writer
+	// 额外的日志输出端
+	sinkBuilders []sinkBuilder
 	// 其他配置项...
 }
 
@@ -191,10 +233,17 @@ func WithLevel(level Level) Option {
 
 // WithJSONFormat enables JSON output format
 // WithJSONFormat 以JSON格式输出日志
-// Klog不支持
+// 等同于 WithFormat(FormatJSON)
 func WithJSONFormat() Option {
+	return WithFormat(FormatJSON)
+}
+
+// WithFormat sets the output format
+// WithFormat 设置日志输出格式：FormatText、FormatJSON、FormatLogfmt、FormatECS、FormatBinary、FormatCEF、FormatLEEF 或 FormatPretty
+// 所有格式默认由本库按 DefaultSchema 统一编码，所有日志实现的输出相同，见 WithNativeEncoder
+func WithFormat(format Format) Option {
 	return func(o *Options) {
-		o.JSONFormat = true
+		o.Format = format
 	}
 }
 
@@ -268,6 +317,8 @@ func WithLogRotation(filePath string, maxSize int, maxBackups int, maxAge int, i
 // WithColor enables color output
 // WithColor 启用颜色输出
 // 启用颜色输出，默认不开启
+// 颜色只作用于文本和 Pretty 格式的控制台输出，文件和 JSON 输出不会包含颜色控制字符（WithNativeEncoder 时的 Klog 除外）
+// 文本格式只为级别着色（WithNativeEncoder 时为日志内容着色），Pretty 格式（WithConsoleFormat(FormatPretty)）为级别、时间、调用信息、字段名和字段值着色
 // 注意：颜色输出会影响性能，建议在开发环境中使用
 func WithColor() Option {
 	return func(o *Options) {
@@ -300,15 +351,50 @@ func WithMark(maskRules ...MaskHandler) Option {
 	}
 }
 
+// WithName 设置日志名称
+// 日志名称会写入 Record.Logger，可用于 WithRoute 按名称路由
+func WithName(name string) Option {
+	return func(o *Options) {
+		o.Name = name
+	}
+}
+
+// WithMaskProcessor 使用指定的脱敏处理器启用脱敏
+// 调用方持有处理器引用，可以通过 MaskProcessor.Stats 获取脱敏审计统计
+func WithMaskProcessor(processor *MaskProcessor) Option {
+	return func(o *Options) {
+		o.MaskEnable = true
+		o.maskProcessor = processor
+	}
+}
+
+// WithMaskDryRun 开启脱敏 dry-run 模式
+// 日志输出保持原样，将被脱敏的字段写入 w，用于新规则的安全上线
+// 需要配合 WithMark 或 WithMaskProcessor 使用
+func WithMaskDryRun(w io.Writer) Option {
+	return func(o *Options) {
+		o.maskDryRunOutput = w
+	}
+}
+
+// WithOutput 添加额外的日志输出，例如 NetworkWriter
+// 日志格式与控制台、文件输出一致
+func WithOutput(writers ...io.Writer) Option {
+	return func(o *Options) {
+		o.outputs = append(o.outputs, writers...)
+	}
+}
+
 // applyOptions applies all options to the Options struct
 // applyOptions 应用所有配置项
 func applyOptions(opts ...Option) Options {
 	options := Options{
-		Level:      defaultLevel,
-		JSONFormat: defaultJSONFormat,
-		AddSource:  defaultAddSource,
-		TimeFormat: time.DateTime,
-		TimeZone:   time.Local.String(),
+		Level:        defaultLevel,
+		Format:       defaultFormat,
+		AddSource:    defaultAddSource,
+		TimeFormat:   time.DateTime,
+		TimeZone:     time.Local.String(),
+		ecsNamespace: defaultECSNamespace,
 	}
 	for _, opt := range opts {
 		opt(&options)
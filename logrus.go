@@ -1,11 +1,14 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -23,30 +26,76 @@ type logrusLogger struct {
 	logger      *logrus.Logger
 	errorLogger *logrus.Logger
 	maskLogger  *MaskProcessor
+	multiline   MultilinePolicy
 	level       Level
 	fields      logrus.Fields
-	colorScheme *ColorScheme
 	AddSource   bool
+	name        string
+	sinks       []Sink
 }
 
 type customTextFormatter struct {
 	logrus.TextFormatter
-	location *time.Location
+	location    *time.Location
+	levelKey    string
+	levelFormat LevelFormat
 }
 
 func (f *customTextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	entry.Time = entry.Time.In(f.location)
-	return f.TextFormatter.Format(entry)
+	data, err := f.TextFormatter.Format(entry)
+	if err != nil || f.levelFormat == LevelFormatDefault {
+		return data, err
+	}
+	// logrus 固定使用小写级别，按 LevelFormat 替换 level=info
+	level := formatLevelString(FromLogrusLoggerLevel(entry.Level), f.levelFormat, "")
+	return replaceLogrusTextLevel(data, f.levelKey, entry.Level.String(), level), nil
 }
 
 type customJSONFormatter struct {
 	logrus.JSONFormatter
-	location *time.Location
+	location    *time.Location
+	levelKey    string
+	levelFormat LevelFormat
 }
 
 func (f *customJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	entry.Time = entry.Time.In(f.location)
-	return f.JSONFormatter.Format(entry)
+	data, err := f.JSONFormatter.Format(entry)
+	if err != nil || f.levelFormat == LevelFormatDefault {
+		return data, err
+	}
+	// logrus 固定使用小写级别，按 LevelFormat 重新编码级别字段，字段顺序与 logrus 一致按 key 排序
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	level, err := json.Marshal(formatLevel(FromLogrusLoggerLevel(entry.Level), f.levelFormat, ""))
+	if err != nil {
+		return nil, err
+	}
+	fields[f.levelKey] = level
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// replaceLogrusTextLevel 替换文本格式中第一个完整的 key=level
+func replaceLogrusTextLevel(data []byte, key, from, to string) []byte {
+	old := []byte(key + "=" + from)
+	for i := 0; ; {
+		j := bytes.Index(data[i:], old)
+		if j < 0 {
+			return data
+		}
+		start, end := i+j, i+j+len(old)
+		if (start == 0 || data[start-1] == ' ') && end < len(data) && (data[end] == ' ' || data[end] == '\n') {
+			return append(append(data[:start:start], key+"="+to...), data[end:]...)
+		}
+		i = end
+	}
 }
 
 var _ Logger = (*logrusLogger)(nil)
@@ -58,57 +107,151 @@ func newLogrusLogger(opts Options) (Logger, error) {
 	if err != nil {
 		return nil, err
 	}
-	if opts.AddSource {
-		logger.SetReportCaller(true)
-		errorLogger.SetReportCaller(true)
+	sinks, err := newSinks(opts)
+	if err != nil {
+		return nil, err
 	}
-
-	if opts.JSONFormat {
-		customFmt := &customJSONFormatter{
-			JSONFormatter: logrus.JSONFormatter{
-				CallerPrettyfier: defaultCallerPrettyfierFunc,
-				TimestampFormat:  opts.TimeFormat,
-			},
-			location: location,
+	customFmt := newLogrusFormatter(opts, opts.Format == FormatJSON, location)
+	logger.SetFormatter(customFmt)
+	errorLogger.SetFormatter(customFmt)
+	logger.SetLevel(ToLogrusLoggerLevel(opts.Level))
+	errorLogger.SetLevel(ToLogrusLoggerLevel(ErrorLevel))
+	// logrus 不支持的格式由 formatOutputs 输出
+	formats := newFormatOutputs(opts, isTextOrJSON)
+	// 额外的输出与 Format 一致，其余输出通过 Hook 使用各自的格式和颜色
+	outputs := io.MultiWriter(opts.outputs...)
+	if len(opts.outputs) > 0 && formats.add(opts.Format, nil, outputs) {
+		outputs = io.Discard
+	}
+	logger.SetOutput(outputs)
+	// 设置日志轮转
+	if opts.LogRotation != nil {
+		logRotation := initLogRotation(opts.LogRotation.FilePath,
+			opts.LogRotation.MaxSize,
+			opts.LogRotation.MaxAge,
+			opts.LogRotation.MaxBackups,
+			opts.LogRotation.Compress)
+		if !formats.addTarget(OutputRotation, nil, logRotation.logger) {
+			logger.AddHook(newLogrusOutputHook(opts, OutputRotation, logRotation.logger, location))
 		}
-
-		logger.SetFormatter(customFmt)
-		errorLogger.SetFormatter(customFmt)
-	} else {
-		customFmt := &customTextFormatter{
-			TextFormatter: logrus.TextFormatter{
-				CallerPrettyfier: defaultCallerPrettyfierFunc,
-				TimestampFormat:  opts.TimeFormat,
-			},
-			location: location,
+	}
+	// 设置文件输出
+	if opts.FilePath != "" && !formats.addTargetFile(OutputFile, nil, opts.FilePath) {
+		logger.AddHook(newLogrusOutputHook(opts, OutputFile, getOutput(opts.FilePath), location))
+	}
+	// 设置控制台输出
+	if console := consoleOutput(opts.Console, os.Stdout); console != nil {
+		if !formats.addTarget(OutputConsole, nil, console) {
+			logger.AddHook(newLogrusOutputHook(opts, OutputConsole, console, location))
 		}
-		logger.SetFormatter(customFmt)
-		errorLogger.SetFormatter(customFmt)
 	}
-	logger.SetLevel(ToLogrusLoggerLevel(opts.Level))
-	errorLogger.SetLevel(ToLogrusLoggerLevel(ErrorLevel))
-	// 设置控制台和文件输出
-	multiWriter := io.MultiWriter(os.Stdout, getOutput(opts.FilePath))
-	logger.SetOutput(multiWriter)
-	errorLogger.SetOutput(getOutput(opts.ErrorOutput))
+	errorLogger.SetOutput(io.Discard)
+	errorNative := opts.ErrorOutput != "" && !formats.addTargetFile(OutputErrorFile, MatchMinLevel(ErrorLevel), opts.ErrorOutput)
+	if errorNative {
+		errorLogger.AddHook(newLogrusOutputHook(opts, OutputErrorFile, getOutput(opts.ErrorOutput), location))
+	}
+	if formats.err != nil {
+		closeSinks(append(sinks, formats.sinks...))
+		return nil, formats.err
+	}
 	logrusLogger := &logrusLogger{
-		logger:      logger,
-		errorLogger: errorLogger,
-		level:       opts.Level,
+		logger: logger,
+		level:  opts.Level,
+		name:   opts.Name,
+		sinks:  append(sinks, formats.sinks...),
 	}
-	// 设置颜色输出
-	if opts.ColorEnabled {
-		logrusLogger.colorScheme = opts.ColorScheme
+	if errorNative {
+		logrusLogger.errorLogger = errorLogger
 	}
 	if opts.AddSource {
 		logrusLogger.AddSource = true
 	}
 	if opts.MaskEnable {
-		logrusLogger.maskLogger = NewMaskProcessor(opts.maskRules...)
+		logrusLogger.maskLogger = newMaskProcessor(opts)
 	}
+	logrusLogger.multiline = opts.multiline
 	return logrusLogger, nil
 }
 
+func newLogrusFormatter(opts Options, json bool, location *time.Location) logrus.Formatter {
+	// logrus 原生的级别为小写，默认转换为与 Record 一致的大写
+	levelFormat := opts.levelFormat
+	if levelFormat == LevelFormatDefault {
+		levelFormat = LevelFormatUpper
+	}
+	// 标准字段的 key
+	fieldMap := logrus.FieldMap{}
+	levelKey := logrus.FieldKeyLevel
+	if opts.fieldKeys.Time != "" {
+		fieldMap[logrus.FieldKeyTime] = opts.fieldKeys.Time
+	}
+	if opts.fieldKeys.Level != "" {
+		fieldMap[logrus.FieldKeyLevel] = opts.fieldKeys.Level
+		levelKey = opts.fieldKeys.Level
+	}
+	if opts.fieldKeys.Message != "" {
+		fieldMap[logrus.FieldKeyMsg] = opts.fieldKeys.Message
+	}
+	if json {
+		return &customJSONFormatter{
+			JSONFormatter: logrus.JSONFormatter{
+				CallerPrettyfier: defaultCallerPrettyfierFunc,
+				TimestampFormat:  opts.TimeFormat,
+				FieldMap:         fieldMap,
+			},
+			location:    location,
+			levelKey:    levelKey,
+			levelFormat: levelFormat,
+		}
+	}
+	return &customTextFormatter{
+		TextFormatter: logrus.TextFormatter{
+			CallerPrettyfier: defaultCallerPrettyfierFunc,
+			TimestampFormat:  opts.TimeFormat,
+			FieldMap:         fieldMap,
+		},
+		location:    location,
+		levelKey:    levelKey,
+		levelFormat: levelFormat,
+	}
+}
+
+// logrusOutputHook 使用独立的格式和颜色写入一个输出
+type logrusOutputHook struct {
+	mu          sync.Mutex
+	writer      io.Writer
+	formatter   logrus.Formatter
+	colorScheme *ColorScheme
+}
+
+func newLogrusOutputHook(opts Options, target OutputTarget, w io.Writer, location *time.Location) *logrusOutputHook {
+	return &logrusOutputHook{
+		writer:      w,
+		formatter:   newLogrusFormatter(opts, opts.outputJSON(target), location),
+		colorScheme: opts.outputColor(target),
+	}
+}
+
+func (h *logrusOutputHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *logrusOutputHook) Fire(entry *logrus.Entry) error {
+	// 复制一份 entry，颜色不影响其他输出
+	e := *entry
+	if h.colorScheme != nil {
+		e.Message = h.colorScheme.Colorize(FromLogrusLoggerLevel(e.Level), e.Message)
+	}
+	data, err := h.formatter.Format(&e)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.writer.Write(data)
+	return err
+}
+
 func ToLogrusLoggerLevel(level Level) logrus.Level {
 	switch level {
 	case DebugLevel:
@@ -143,15 +286,29 @@ func FromLogrusLoggerLevel(level logrus.Level) Level {
 	}
 }
 
+// log 按 MultilinePolicy 处理日志内容后写入，拆分后的每条日志单独写入
 func (l *logrusLogger) log(level logrus.Level, msg string, args ...any) {
-	// 创建基础 fields
-	fields := make(logrus.Fields)
+	if l.multiline == MultilineKeep {
+		l.write(level, msg, args...)
+		return
+	}
+	for _, part := range applyMultiline(l.multiline, msg, args) {
+		l.write(level, part.msg, part.args...)
+	}
+}
 
-	// 添加颜色格式化
-	if l.colorScheme != nil {
-		msg = l.colorScheme.Colorize(FromLogrusLoggerLevel(level), msg)
+func (l *logrusLogger) write(level logrus.Level, msg string, args ...any) {
+	// 如果有脱敏处理器，先处理值
+	if l.maskLogger != nil {
+		args = l.maskLogger.Process(args...)
+	}
+	if enabled := l.logger.IsLevelEnabled(level); sinksEnabled(l.sinks, FromLogrusLoggerLevel(level), enabled) {
+		writeSinks(l.sinks, enabled, newRecord(l.name, FromLogrusLoggerLevel(level), msg, l.AddSource, 4, fieldsToArgs(l.fields), args))
 	}
 
+	// 创建基础 fields
+	fields := make(logrus.Fields)
+
 	// 添加固定字段
 	if l.fields != nil {
 		for k, v := range l.fields {
@@ -159,27 +316,26 @@ func (l *logrusLogger) log(level logrus.Level, msg string, args ...any) {
 		}
 	}
 
+	if l.name != "" {
+		fields[defaultSchema.LoggerKey] = l.name
+	}
 	// 添加调用源信息
 	if l.AddSource {
-		fields["source"] = getCaller(3)
+		fields[defaultSchema.CallerKey] = getCaller(4)
 	}
 
 	// 处理 KV 参数
 	if len(args) > 0 {
 		// 确保参数是偶数个
 		if len(args)%2 != 0 {
-			args = append(args, "MISSING_VALUE")
+			// 限制容量，避免 append 写入调用方的底层数组
+			args = append(args[:len(args):len(args)], "MISSING_VALUE")
 		}
 
 		// 将 KV 参数转换为 fields
 		for i := 0; i < len(args); i += 2 {
 			if key, ok := args[i].(string); ok {
-				// 如果有脱敏处理器，先处理值
-				if l.maskLogger != nil {
-					fields[key] = l.maskLogger.Process(args[i], args[i+1])[1]
-				} else {
-					fields[key] = args[i+1]
-				}
+				fields[key] = args[i+1]
 			}
 		}
 	}
@@ -195,8 +351,8 @@ func (l *logrusLogger) log(level logrus.Level, msg string, args ...any) {
 			errorFields[k] = v
 		}
 		// 确保错误日志有源信息
-		if _, exists := errorFields["source"]; !exists {
-			errorFields["source"] = getCaller(3)
+		if _, exists := errorFields[defaultSchema.CallerKey]; !exists {
+			errorFields[defaultSchema.CallerKey] = getCaller(4)
 		}
 		l.errorLogger.WithFields(errorFields).Log(level, msg)
 	}
@@ -227,9 +383,27 @@ func (l *logrusLogger) Fatalf(format string, args ...any) {
 func (l *logrusLogger) SetLevel(level Level) { l.logger.SetLevel(logrus.Level(level)) }
 
 func (l *logrusLogger) WithFields(fields map[string]any) Logger {
+	// 持久化字段与单次调用参数走同一条脱敏链路
+	if l.maskLogger != nil {
+		fields = l.maskLogger.ProcessFields(fields)
+	}
+	fields = multilineFields(l.multiline, fields)
+	newFields := make(logrus.Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		newFields[k] = v
+	}
+	for k, v := range fields {
+		newFields[k] = v
+	}
 	return &logrusLogger{
-		logger: l.logger,
-		level:  l.level,
-		fields: logrus.Fields(fields),
+		logger:      l.logger,
+		errorLogger: l.errorLogger,
+		maskLogger:  l.maskLogger,
+		multiline:   l.multiline,
+		level:       l.level,
+		fields:      newFields,
+		AddSource:   l.AddSource,
+		name:        l.name,
+		sinks:       l.sinks,
 	}
 }
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// MultilinePolicy 日志内容和字符串字段包含换行或控制字符时的处理方式
+type MultilinePolicy int
+
+const (
+	// MultilineKeep 不处理，默认；JSON 和大部分文本格式会转义换行，zap 的文本格式原样输出
+	MultilineKeep MultilinePolicy = iota
+	// MultilineEscape 将换行、制表符和其他控制字符转义为 \n、\t、\x1b 等，每条日志只占一行
+	MultilineEscape
+	// MultilineIndent 后续行以制表符开头，便于按行采集的工具把以空白开头的行合并到上一行，其他控制字符转义
+	// 只对文本和 Pretty 格式生效，文本格式的输出由本库编码（包括设置了 WithNativeEncoder 时），JSON 等格式中换行仍会转义
+	MultilineIndent
+	// MultilineSplit 日志内容按行拆分为多条日志，共享 seq_id 字段，seq_part 为从 1 开始的序号，seq_total 为总条数；
+	// 每条日志都带有全部字段，字符串字段中的控制字符转义
+	MultilineSplit
+)
+
+// 拆分日志时添加的字段
+const (
+	multilineSeqIDKey    = "seq_id"
+	multilineSeqPartKey  = "seq_part"
+	multilineSeqTotalKey = "seq_total"
+)
+
+// WithMultilinePolicy sets how messages and string fields containing newlines are written
+// WithMultilinePolicy 设置日志内容和字符串字段包含换行或控制字符时的处理方式，对所有日志实现和输出生效
+// 例如通过 Infof 输出的堆栈和 SQL 语句，避免破坏按行采集的日志收集工具
+func WithMultilinePolicy(policy MultilinePolicy) Option {
+	return func(o *Options) {
+		o.multiline = policy
+	}
+}
+
+// multilinePart 处理后的一条日志
+type multilinePart struct {
+	msg  string
+	args []any
+}
+
+var (
+	multilineSeqPrefix  = newMultilineSeqPrefix()
+	multilineSeqCounter atomic.Uint64
+)
+
+// newMultilineSeqPrefix 进程内唯一的前缀，多个进程写入同一个采集端时 seq_id 不会重复
+func newMultilineSeqPrefix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// applyMultiline 按策略处理日志内容和参数中的字符串值，MultilineSplit 时可能返回多条日志
+// 不修改调用方的 args
+func applyMultiline(policy MultilinePolicy, msg string, args []any) []multilinePart {
+	args = multilineArgs(policy.fieldPolicy(), args)
+	if policy != MultilineSplit {
+		return []multilinePart{{msg: multilineString(policy, msg), args: args}}
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(msg, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) == 1 {
+		return []multilinePart{{msg: multilineString(MultilineEscape, msg), args: args}}
+	}
+	id := multilineSeqPrefix + "-" + strconv.FormatUint(multilineSeqCounter.Add(1), 10)
+	parts := make([]multilinePart, len(lines))
+	for i, line := range lines {
+		partArgs := make([]any, 0, len(args)+6)
+		partArgs = append(partArgs, args...)
+		if len(partArgs)%2 != 0 {
+			// 奇数个参数时补齐，避免序号字段错位
+			partArgs = append(partArgs, nil)
+		}
+		partArgs = append(partArgs, multilineSeqIDKey, id, multilineSeqPartKey, i+1, multilineSeqTotalKey, len(lines))
+		parts[i] = multilinePart{msg: multilineString(MultilineEscape, line), args: partArgs}
+	}
+	return parts
+}
+
+// fieldPolicy 字段使用的策略，字段不能拆分，MultilineSplit 时转义
+func (p MultilinePolicy) fieldPolicy() MultilinePolicy {
+	if p == MultilineSplit {
+		return MultilineEscape
+	}
+	return p
+}
+
+// multilineArgs 处理参数中的字符串值，需要修改时返回新的切片
+func multilineArgs(policy MultilinePolicy, args []any) []any {
+	var out []any
+	for i := 1; i < len(args); i += 2 {
+		s, ok := args[i].(string)
+		if !ok || !hasControl(s) {
+			continue
+		}
+		if out == nil {
+			out = append([]any(nil), args...)
+		}
+		out[i] = multilineString(policy, s)
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// multilineString 按策略处理字符串，MultilineIndent 之外的策略转义所有控制字符
+func multilineString(policy MultilinePolicy, s string) string {
+	if policy == MultilineKeep || !hasControl(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case policy == MultilineIndent && c == '\r' && i+1 < len(s) && s[i+1] == '\n':
+			// \r\n 按一个换行处理
+		case policy == MultilineIndent && c == '\n':
+			b.WriteString("\n\t")
+		case policy == MultilineIndent && c == '\t':
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < ' ' || c == 0x7f:
+			b.WriteString(`\x`)
+			b.WriteString(hex.EncodeToString([]byte{c}))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// hasControl 是否包含换行等 ASCII 控制字符，多字节 UTF-8 字符不受影响
+func hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// multilineFields 处理 WithFields 持久化字段中的字符串值，需要修改时返回新的 map
+func multilineFields(policy MultilinePolicy, fields map[string]any) map[string]any {
+	policy = policy.fieldPolicy()
+	if policy == MultilineKeep {
+		return fields
+	}
+	var out map[string]any
+	for k, v := range fields {
+		s, ok := v.(string)
+		if !ok || !hasControl(s) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, len(fields))
+			for k, v := range fields {
+				out[k] = v
+			}
+		}
+		out[k] = multilineString(policy, s)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
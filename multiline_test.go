@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const multilineSQL = "SELECT id\nFROM users\r\nWHERE name = 'a\tb'\n"
+
+func TestMultilineEscape(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithMultilinePolicy(MultilineEscape),
+				WithConsole(ConsoleNone),
+				WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.WithFields(map[string]any{"query": "a\nb"}).Infof("query: %s", multilineSQL)
+
+			got := strings.TrimSuffix(out.String(), "\n")
+			if strings.Count(got, "\n") != 0 || got == "" {
+				t.Fatalf("output is not a single line: %q", out.String())
+			}
+			if !strings.Contains(got, "FROM users") || !strings.Contains(got, "query") {
+				t.Fatalf("output = %q", got)
+			}
+		})
+	}
+}
+
+func TestMultilineIndent(t *testing.T) {
+	cases := []struct {
+		name   string
+		format Format
+		native bool
+	}{
+		{"text", FormatText, false},
+		{"native-text", FormatText, true},
+		{"pretty", FormatPretty, false},
+	}
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		for _, c := range cases {
+			t.Run(string(typ)+"/"+c.name, func(t *testing.T) {
+				out := &syncBuffer{}
+				opts := []Option{
+					WithMultilinePolicy(MultilineIndent),
+					WithFormat(c.format),
+					WithConsole(ConsoleNone),
+					WithOutput(out)}
+				if c.native {
+					opts = append(opts, WithNativeEncoder())
+				}
+				logger, err := NewLoggerWithType(typ, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				logger.Error("panic recovered\ngoroutine 1 [running]:\nmain.run()", "trace", "a\nb")
+
+				lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+				if len(lines) < 4 {
+					t.Fatalf("output = %q", out.String())
+				}
+				for _, line := range lines[1:] {
+					if line == "" || (line[0] != ' ' && line[0] != '\t') {
+						t.Fatalf("continuation line %q is not indented: %q", line, out.String())
+					}
+				}
+				if c.format == FormatText && !strings.Contains(out.String(), `msg="panic recovered`+"\n\tgoroutine 1 [running]:\n\tmain.run()\"") {
+					t.Fatalf("output = %q", out.String())
+				}
+			})
+		}
+	}
+}
+
+func TestMultilineSplit(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithMultilinePolicy(MultilineSplit),
+				WithFormat(FormatJSON),
+				WithConsole(ConsoleNone),
+				WithOutput(out),
+				WithAddSource())
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info(multilineSQL, "user", "alice\nbob")
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("got %d records: %q", len(lines), out.String())
+			}
+			var seqID string
+			wantMsg := []string{"SELECT id", "FROM users", `WHERE name = 'a\tb'`}
+			for i, line := range lines {
+				var m map[string]any
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("record %q: %v", line, err)
+				}
+				if m["msg"] != wantMsg[i] {
+					t.Fatalf("record %d = %v, want msg %q", i, m, wantMsg[i])
+				}
+				if m["user"] != `alice\nbob` || m["seq_part"] != float64(i+1) || m["seq_total"] != float64(3) {
+					t.Fatalf("record %d = %v", i, m)
+				}
+				id, _ := m["seq_id"].(string)
+				if id == "" || (seqID != "" && id != seqID) {
+					t.Fatalf("record %d seq_id = %v, want %q", i, m["seq_id"], seqID)
+				}
+				seqID = id
+				if !strings.Contains(line, "multiline_test.go") {
+					t.Fatalf("record %d caller is not the test file: %q", i, line)
+				}
+			}
+		})
+	}
+}
+
+func TestMultilineKeep(t *testing.T) {
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(SlogLogger, WithConsole(ConsoleNone), WithOutput(out), WithFormat(FormatJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("a\nb")
+	if !strings.Contains(out.String(), `"a\nb"`) {
+		t.Fatalf("output = %q", out.String())
+	}
+}
+
+func TestApplyMultilineDoesNotModifyArgs(t *testing.T) {
+	args := []any{"k", "a\nb"}
+	parts := applyMultiline(MultilineSplit, "x\ny", args)
+	if len(parts) != 2 || args[1] != "a\nb" || parts[0].args[1] != `a\nb` {
+		t.Fatalf("parts = %v, args = %v", parts, args)
+	}
+	if got := multilineString(MultilineEscape, "a\x1bb"); got != `a\x1bb` {
+		t.Fatalf("escape = %q", got)
+	}
+	if got := multilineString(MultilineIndent, "a\r\nb"); got != "a\n\tb" {
+		t.Fatalf("indent = %q", got)
+	}
+}
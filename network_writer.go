@@ -0,0 +1,424 @@
+package logger
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultNetworkDialTimeout  = 5 * time.Second
+	defaultNetworkWriteTimeout = 5 * time.Second
+	defaultNetworkMinBackoff   = 100 * time.Millisecond
+	defaultNetworkMaxBackoff   = 30 * time.Second
+	defaultNetworkSpoolSize    = 64 << 20 // 64MB
+	networkSpoolFileName       = "network.spool"
+)
+
+// ErrSpoolFull 缓冲区已满，日志被丢弃
+var ErrSpoolFull = errors.New("network writer spool is full")
+
+// NetworkWriterConfig 网络输出配置
+type NetworkWriterConfig struct {
+	// Network 网络类型：tcp、udp、unix、unixgram
+	Network string
+	// Addr 远端地址，unix 套接字为文件路径
+	Addr string
+	// TLSConfig 不为 nil 时使用 TLS 连接，仅支持 tcp
+	TLSConfig *tls.Config
+	// DialTimeout 连接超时，默认 5s
+	DialTimeout time.Duration
+	// WriteTimeout 写超时，默认 5s
+	WriteTimeout time.Duration
+	// MinBackoff 重连最小间隔，默认 100ms，每次失败翻倍
+	MinBackoff time.Duration
+	// MaxBackoff 重连最大间隔，默认 30s
+	MaxBackoff time.Duration
+	// SpoolDir 远端不可用时的本地磁盘缓冲目录，为空时缓冲在内存中
+	// 进程重启后会继续重放磁盘中未发送的日志，重放中断时可能产生重复
+	SpoolDir string
+	// SpoolMaxBytes 缓冲区最大字节数，默认 64MB，超出后丢弃新日志
+	SpoolMaxBytes int64
+}
+
+// NetworkWriter 网络日志输出
+// 每次 Write 作为一条日志发送，远端不可用时写入缓冲区并在后台以指数退避重连，
+// 重连成功后按顺序重放缓冲区中的日志。可通过 WithOutput 添加到任意日志实现
+type NetworkWriter struct {
+	cfg     NetworkWriterConfig
+	mu      sync.Mutex
+	conn    net.Conn
+	spool   networkSpool
+	dropped atomic.Uint64
+
+	wake    chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+var _ io.WriteCloser = (*NetworkWriter)(nil)
+
+// NewNetworkWriter 创建网络日志输出
+// 创建时不要求远端可用，连接失败的日志会进入缓冲区
+func NewNetworkWriter(cfg NetworkWriterConfig) (*NetworkWriter, error) {
+	if cfg.Network == "" || cfg.Addr == "" {
+		return nil, errors.New("network writer requires network and addr")
+	}
+	if cfg.TLSConfig != nil && !isStreamNetwork(cfg.Network) {
+		return nil, errors.New("tls is only supported on stream networks")
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = defaultNetworkDialTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultNetworkWriteTimeout
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultNetworkMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = defaultNetworkMaxBackoff
+	}
+	if cfg.SpoolMaxBytes <= 0 {
+		cfg.SpoolMaxBytes = defaultNetworkSpoolSize
+	}
+
+	var spool networkSpool = &memorySpool{maxBytes: cfg.SpoolMaxBytes}
+	if cfg.SpoolDir != "" {
+		fileSpool, err := openFileSpool(filepath.Join(cfg.SpoolDir, networkSpoolFileName), cfg.SpoolMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		spool = fileSpool
+	}
+	w := &NetworkWriter{
+		cfg:     cfg,
+		spool:   spool,
+		wake:    make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	// 连接远端，并重放上次进程退出时未发送的日志
+	w.signal()
+	return w, nil
+}
+
+// Write 发送一条日志，远端不可用时写入缓冲区
+// 只有缓冲区已满时返回 ErrSpoolFull
+func (w *NetworkWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// 缓冲区有未发送的日志时必须先排队，保证顺序
+	if w.conn != nil && w.spool.Len() == 0 {
+		if err := w.writeConn(p); err == nil {
+			return len(p), nil
+		}
+		w.closeConn()
+	}
+	if err := w.spool.Push(p); err != nil {
+		w.dropped.Add(1)
+		return 0, err
+	}
+	w.signal()
+	return len(p), nil
+}
+
+// Dropped 返回因缓冲区已满被丢弃的日志条数
+func (w *NetworkWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close 尝试发送缓冲区中的日志后关闭连接
+// 磁盘缓冲中未发送的日志会保留到下次启动
+func (w *NetworkWriter) Close() error {
+	w.once.Do(func() {
+		close(w.closing)
+		<-w.done
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		_ = w.drain()
+	}
+	w.closeConn()
+	return w.spool.Close()
+}
+
+func (w *NetworkWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 后台重连并重放缓冲区
+func (w *NetworkWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.closing:
+			return
+		case <-w.wake:
+		}
+		backoff := w.cfg.MinBackoff
+		for !w.flush() {
+			select {
+			case <-w.closing:
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > w.cfg.MaxBackoff {
+				backoff = w.cfg.MaxBackoff
+			}
+		}
+	}
+}
+
+// flush 建立连接并重放缓冲区，全部发送成功时返回 true
+func (w *NetworkWriter) flush() bool {
+	w.mu.Lock()
+	connected := w.conn != nil
+	w.mu.Unlock()
+	if !connected {
+		conn, err := w.dial()
+		if err != nil {
+			return false
+		}
+		w.mu.Lock()
+		w.conn = conn
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.drain(); err != nil {
+		w.closeConn()
+		return false
+	}
+	return true
+}
+
+// drain 按顺序发送缓冲区中的日志，调用方需持有锁
+func (w *NetworkWriter) drain() error {
+	for w.spool.Len() > 0 {
+		p, err := w.spool.Peek()
+		if err != nil {
+			return err
+		}
+		if err := w.writeConn(p); err != nil {
+			return err
+		}
+		if err := w.spool.Pop(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *NetworkWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.cfg.DialTimeout}
+	if w.cfg.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, w.cfg.Network, w.cfg.Addr, w.cfg.TLSConfig)
+	}
+	return dialer.Dial(w.cfg.Network, w.cfg.Addr)
+}
+
+func (w *NetworkWriter) writeConn(p []byte) error {
+	if w.conn == nil {
+		return net.ErrClosed
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	_, err := w.conn.Write(p)
+	return err
+}
+
+func (w *NetworkWriter) closeConn() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func isStreamNetwork(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// networkSpool 远端不可用时的日志缓冲区，按写入顺序读取
+type networkSpool interface {
+	Push(p []byte) error
+	Peek() ([]byte, error)
+	Pop() error
+	Len() int
+	Close() error
+}
+
+// memorySpool 内存缓冲区
+type memorySpool struct {
+	records  [][]byte
+	size     int64
+	maxBytes int64
+}
+
+func (s *memorySpool) Push(p []byte) error {
+	if s.size+int64(len(p)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+	// 调用方可能复用 p，需要复制
+	s.records = append(s.records, append([]byte(nil), p...))
+	s.size += int64(len(p))
+	return nil
+}
+
+func (s *memorySpool) Peek() ([]byte, error) {
+	if len(s.records) == 0 {
+		return nil, io.EOF
+	}
+	return s.records[0], nil
+}
+
+func (s *memorySpool) Pop() error {
+	if len(s.records) == 0 {
+		return io.EOF
+	}
+	s.size -= int64(len(s.records[0]))
+	s.records[0] = nil
+	s.records = s.records[1:]
+	return nil
+}
+
+func (s *memorySpool) Len() int { return len(s.records) }
+
+func (s *memorySpool) Close() error { return nil }
+
+// fileSpool 磁盘缓冲区
+// 文件中每条日志以 4 字节大端长度开头，全部发送后截断文件
+type fileSpool struct {
+	file     *os.File
+	offset   int64 // 下一条待发送日志的位置
+	size     int64
+	count    int
+	maxBytes int64
+}
+
+func openFileSpool(filePath string, maxBytes int64) (*fileSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &fileSpool{file: file, maxBytes: maxBytes}
+	// 统计上次遗留的日志条数，不完整的尾部记录直接截断
+	var header [4]byte
+	for {
+		if _, err := file.ReadAt(header[:], s.size); err != nil {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(header[:]))
+		info, err := file.Stat()
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		if s.size+4+n > info.Size() {
+			break
+		}
+		s.size += 4 + n
+		s.count++
+	}
+	if err := file.Truncate(s.size); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSpool) Push(p []byte) error {
+	if s.size-s.offset+int64(len(p)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+	buf := make([]byte, 4+len(p))
+	binary.BigEndian.PutUint32(buf, uint32(len(p)))
+	copy(buf[4:], p)
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		return err
+	}
+	s.size += int64(len(buf))
+	s.count++
+	return nil
+}
+
+func (s *fileSpool) Peek() ([]byte, error) {
+	if s.count == 0 {
+		return nil, io.EOF
+	}
+	var header [4]byte
+	if _, err := s.file.ReadAt(header[:], s.offset); err != nil {
+		return nil, err
+	}
+	p := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := s.file.ReadAt(p, s.offset+4); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *fileSpool) Pop() error {
+	if s.count == 0 {
+		return io.EOF
+	}
+	var header [4]byte
+	if _, err := s.file.ReadAt(header[:], s.offset); err != nil {
+		return err
+	}
+	s.offset += 4 + int64(binary.BigEndian.Uint32(header[:]))
+	s.count--
+	if s.count == 0 {
+		s.offset, s.size = 0, 0
+		return s.file.Truncate(0)
+	}
+	return nil
+}
+
+func (s *fileSpool) Len() int { return s.count }
+
+func (s *fileSpool) Close() error {
+	// 保留未发送的日志，下次启动时从文件头开始重放
+	if s.offset > 0 && s.count > 0 {
+		if err := s.compact(); err != nil {
+			_ = s.file.Close()
+			return err
+		}
+	}
+	return s.file.Close()
+}
+
+// compact 移除已发送的日志
+func (s *fileSpool) compact() error {
+	remaining := make([]byte, s.size-s.offset)
+	if _, err := s.file.ReadAt(remaining, s.offset); err != nil {
+		return err
+	}
+	if _, err := s.file.WriteAt(remaining, 0); err != nil {
+		return err
+	}
+	s.offset, s.size = 0, int64(len(remaining))
+	return s.file.Truncate(s.size)
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeTCPAddr 返回一个当前无人监听的本地地址
+func freeTCPAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+// acceptLines 接受连接并把收到的每一行发送到 channel
+func acceptLines(t *testing.T, ln net.Listener) <-chan string {
+	t.Helper()
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	return lines
+}
+
+func expectLines(t *testing.T, lines <-chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-lines:
+			if !strings.Contains(got, w) {
+				t.Fatalf("line = %q, want %q", got, w)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout waiting for %q", w)
+		}
+	}
+}
+
+func TestNetworkWriterReconnectAndReplay(t *testing.T) {
+	addr := freeTCPAddr(t)
+	w, err := NewNetworkWriter(NetworkWriterConfig{
+		Network:    "tcp",
+		Addr:       addr,
+		SpoolDir:   t.TempDir(),
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 远端不可用时写入缓冲区
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := acceptLines(t, ln)
+	expectLines(t, lines, "one", "two", "three")
+
+	if _, err := w.Write([]byte("four\n")); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, lines, "four")
+}
+
+func TestNetworkWriterPersistentSpool(t *testing.T) {
+	addr := freeTCPAddr(t)
+	dir := t.TempDir()
+	cfg := NetworkWriterConfig{Network: "tcp", Addr: addr, SpoolDir: dir, MinBackoff: 10 * time.Millisecond}
+	w, err := NewNetworkWriter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("before restart 1\n"))
+	_, _ = w.Write([]byte("before restart 2\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := acceptLines(t, ln)
+
+	w, err = NewNetworkWriter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	expectLines(t, lines, "before restart 1", "before restart 2")
+}
+
+func TestNetworkWriterSpoolFull(t *testing.T) {
+	w, err := NewNetworkWriter(NetworkWriterConfig{
+		Network:       "tcp",
+		Addr:          freeTCPAddr(t),
+		SpoolMaxBytes: 10,
+		MinBackoff:    time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("12345\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("67890\n")); !errors.Is(err, ErrSpoolFull) {
+		t.Fatalf("err = %v, want ErrSpoolFull", err)
+	}
+	if w.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want 1", w.Dropped())
+	}
+}
+
+func TestNetworkWriterWithOutput(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := acceptLines(t, ln)
+
+	for _, loggerType := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		t.Run(string(loggerType), func(t *testing.T) {
+			w, err := NewNetworkWriter(NetworkWriterConfig{Network: "tcp", Addr: ln.Addr().String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close()
+			logger, err := NewLoggerWithType(loggerType, WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithOutput(w))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("shipped over network", "backend", string(loggerType))
+			expectLines(t, lines, "shipped over network")
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultOTLPScopeName  = "github.com/piwriw/go-logger"
+	defaultOTLPTraceIDKey = "trace_id"
+	defaultOTLPSpanIDKey  = "span_id"
+)
+
+// OTLPConfig OTLP/HTTP 日志导出配置
+type OTLPConfig struct {
+	// HTTP 批量发送配置，URL 为 OTLP/HTTP 日志接收地址，例如 http://localhost:4318/v1/logs
+	HTTP HTTPSinkConfig
+	// ServiceName 资源属性 service.name，默认 unknown_service:<进程名>
+	ServiceName string
+	// ResourceAttributes 其他资源属性，例如 service.version、deployment.environment
+	ResourceAttributes map[string]any
+	// ScopeName InstrumentationScope 名称，默认为本库的模块路径
+	ScopeName string
+	// TraceIDKey 写入 traceId 的字段名，默认 trace_id，值为 32 位十六进制字符串
+	TraceIDKey string
+	// SpanIDKey 写入 spanId 的字段名，默认 span_id，值为 16 位十六进制字符串
+	SpanIDKey string
+}
+
+// NewOTLPSink 创建 OTLP/HTTP 日志导出输出端
+// 日志按 OpenTelemetry 日志数据模型编码为 OTLP/JSON LogRecord，每批作为一个 ExportLogsServiceRequest 发送，
+// 批量、重试和死信文件与 HTTPSink 一致，退出前需要调用 Close 发送剩余日志
+// 通过 WithSink 添加到日志实例，输出端由调用方持有，例如：
+//
+//	sink, err := logger.NewOTLPSink(logger.OTLPConfig{HTTP: logger.HTTPSinkConfig{URL: "http://localhost:4318/v1/logs"}})
+//	log, err := logger.NewLogger(logger.WithSink(sink))
+//	defer sink.Close()
+func NewOTLPSink(cfg OTLPConfig) (*HTTPSink, error) {
+	if cfg.HTTP.URL == "" {
+		return nil, errors.New("otlp sink requires url")
+	}
+	encoder := newOTLPEncoder(cfg)
+	return newHTTPSink(cfg.HTTP, encoder.encodeLogRecord, encoder.frame, "application/json")
+}
+
+// OTLPSeverityNumber 将日志级别转换为 OpenTelemetry SeverityNumber
+func OTLPSeverityNumber(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 5
+	case InfoLevel:
+		return 9
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	case FatalLevel:
+		return 21
+	default:
+		return 0 // SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+// otlpEncoder OTLP/JSON 编码
+type otlpEncoder struct {
+	traceIDKey string
+	spanIDKey  string
+	prefix     []byte // ExportLogsServiceRequest 中 logRecords 之前的部分
+}
+
+func newOTLPEncoder(cfg OTLPConfig) *otlpEncoder {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	if cfg.ScopeName == "" {
+		cfg.ScopeName = defaultOTLPScopeName
+	}
+	if cfg.TraceIDKey == "" {
+		cfg.TraceIDKey = defaultOTLPTraceIDKey
+	}
+	if cfg.SpanIDKey == "" {
+		cfg.SpanIDKey = defaultOTLPSpanIDKey
+	}
+	resource := map[string]any{"service.name": cfg.ServiceName}
+	for k, v := range cfg.ResourceAttributes {
+		resource[k] = v
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"resourceLogs":[{"resource":{"attributes":`)
+	writeOTLPAttributes(&buf, appendFields(nil, fieldsToArgs(resource)))
+	buf.WriteString(`},"scopeLogs":[{"scope":{"name":`)
+	writeJSONValue(&buf, cfg.ScopeName)
+	buf.WriteString(`},"logRecords":[`)
+	return &otlpEncoder{traceIDKey: cfg.TraceIDKey, spanIDKey: cfg.SpanIDKey, prefix: buf.Bytes()}
+}
+
+// frame 将换行分隔的 LogRecord 组合为 ExportLogsServiceRequest
+// JSON 字符串中的换行已转义，可以直接按换行分隔
+func (e *otlpEncoder) frame(batch []byte) []byte {
+	records := bytes.ReplaceAll(bytes.TrimSuffix(batch, []byte{'\n'}), []byte{'\n'}, []byte{','})
+	body := make([]byte, 0, len(e.prefix)+len(records)+8)
+	body = append(body, e.prefix...)
+	body = append(body, records...)
+	return append(body, "]}]}]}"...)
+}
+
+// encodeLogRecord 编码一条 LogRecord
+// 调用信息写入 code.filepath/code.lineno，日志名称写入 logger.name，trace_id/span_id 字段写入 traceId/spanId
+func (e *otlpEncoder) encodeLogRecord(r *Record) ([]byte, error) {
+	var buf bytes.Buffer
+	timestamp := strconv.FormatInt(r.Time.UnixNano(), 10)
+	buf.WriteString(`{"timeUnixNano":"` + timestamp + `","observedTimeUnixNano":"` + timestamp + `"`)
+	buf.WriteString(`,"severityNumber":` + strconv.Itoa(OTLPSeverityNumber(r.Level)))
+	buf.WriteString(`,"severityText":`)
+	writeJSONValue(&buf, r.Level.String())
+	buf.WriteString(`,"body":`)
+	writeOTLPValue(&buf, r.Message)
+
+	attributes := make([]Field, 0, len(r.Fields)+3)
+	if r.Logger != "" {
+		attributes = append(attributes, Field{Key: "logger.name", Value: r.Logger})
+	}
+	if r.File != "" {
+		attributes = append(attributes, Field{Key: "code.filepath", Value: r.File}, Field{Key: "code.lineno", Value: r.Line})
+	}
+	var traceID, spanID string
+	for _, f := range r.Fields {
+		switch {
+		case f.Key == e.traceIDKey && isHexID(f.Value, 16):
+			traceID = fmt.Sprint(f.Value)
+		case f.Key == e.spanIDKey && isHexID(f.Value, 8):
+			spanID = fmt.Sprint(f.Value)
+		default:
+			attributes = append(attributes, f)
+		}
+	}
+	if len(attributes) > 0 {
+		buf.WriteString(`,"attributes":`)
+		writeOTLPAttributes(&buf, attributes)
+	}
+	if traceID != "" {
+		buf.WriteString(`,"traceId":"` + traceID + `"`)
+	}
+	if spanID != "" {
+		buf.WriteString(`,"spanId":"` + spanID + `"`)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// isHexID 判断值是否为指定字节数的十六进制 ID，全 0 的 ID 无效
+func isHexID(v any, size int) bool {
+	s := fmt.Sprint(v)
+	if len(s) != size*2 {
+		return false
+	}
+	id, err := hex.DecodeString(s)
+	if err != nil {
+		return false
+	}
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func writeOTLPAttributes(buf *bytes.Buffer, fields []Field) {
+	buf.WriteByte('[')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"key":`)
+		writeJSONValue(buf, f.Key)
+		buf.WriteString(`,"value":`)
+		writeOTLPValue(buf, f.Value)
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+}
+
+// writeOTLPValue 编码 AnyValue，64 位整数按 OTLP/JSON 约定编码为字符串
+func writeOTLPValue(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString(`{}`)
+	case string:
+		buf.WriteString(`{"stringValue":`)
+		writeJSONValue(buf, val)
+		buf.WriteByte('}')
+	case bool:
+		buf.WriteString(`{"boolValue":` + strconv.FormatBool(val) + `}`)
+	case int, int8, int16, int32, int64:
+		buf.WriteString(`{"intValue":"` + strconv.FormatInt(reflect.ValueOf(val).Int(), 10) + `"}`)
+	case uint, uint8, uint16, uint32, uint64:
+		u := reflect.ValueOf(val).Uint()
+		if u > math.MaxInt64 {
+			writeOTLPValue(buf, strconv.FormatUint(u, 10))
+			return
+		}
+		buf.WriteString(`{"intValue":"` + strconv.FormatUint(u, 10) + `"}`)
+	case float32, float64:
+		f := reflect.ValueOf(val).Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			writeOTLPValue(buf, strconv.FormatFloat(f, 'g', -1, 64))
+			return
+		}
+		buf.WriteString(`{"doubleValue":` + strconv.FormatFloat(f, 'g', -1, 64) + `}`)
+	case []byte:
+		buf.WriteString(`{"bytesValue":"` + base64.StdEncoding.EncodeToString(val) + `"}`)
+	case error:
+		writeOTLPValue(buf, val.Error())
+	case time.Time:
+		writeOTLPValue(buf, val.Format(time.RFC3339Nano))
+	case time.Duration:
+		writeOTLPValue(buf, val.String())
+	case fmt.Stringer:
+		writeOTLPValue(buf, val.String())
+	default:
+		writeOTLPComposite(buf, val)
+	}
+}
+
+// writeOTLPComposite 切片编码为 arrayValue，string 为 key 的 map 编码为 kvlistValue，其他类型使用 fmt.Sprint 文本
+func writeOTLPComposite(buf *bytes.Buffer, v any) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		buf.WriteString(`{"arrayValue":{"values":[`)
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeOTLPValue(buf, rv.Index(i).Interface())
+		}
+		buf.WriteString(`]}}`)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			writeOTLPValue(buf, fmt.Sprint(v))
+			return
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		fields := make([]Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, Field{Key: k, Value: rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()})
+		}
+		buf.WriteString(`{"kvlistValue":{"values":`)
+		writeOTLPAttributes(buf, fields)
+		buf.WriteString(`}}`)
+	default:
+		writeOTLPValue(buf, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOTLPSink(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewOTLPSink(OTLPConfig{
+		HTTP:               HTTPSinkConfig{URL: server.URL + "/v1/logs", FlushInterval: time.Hour},
+		ServiceName:        "checkout",
+		ResourceAttributes: map[string]any{"service.version": "1.2.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLoggerWithType(ZapLogger, WithConsole(ConsoleNone), WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithAddSource(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Warn("payment slow",
+		"trace_id", "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id", "00f067aa0ba902b7",
+		"amount", 42,
+		"tags", []string{"eu", "card"})
+	logger.Info("done")
+	_ = sink.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	resourceLogs := requests[0]["resourceLogs"].([]any)[0].(map[string]any)
+	resource := otlpAttributes(resourceLogs["resource"].(map[string]any)["attributes"])
+	if resource["service.name"] != "checkout" || resource["service.version"] != "1.2.0" {
+		t.Fatalf("unexpected resource: %v", resource)
+	}
+	records := resourceLogs["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any)
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	record := records[0].(map[string]any)
+	if record["severityNumber"] != float64(13) || record["severityText"] != "WARN" || record["body"].(map[string]any)["stringValue"] != "payment slow" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+	if record["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || record["spanId"] != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected trace context: %v", record)
+	}
+	attributes := otlpAttributes(record["attributes"])
+	if attributes["amount"] != "42" || attributes["code.lineno"] == nil || attributes["trace_id"] != nil {
+		t.Fatalf("unexpected attributes: %v", attributes)
+	}
+	if tags, _ := attributes["tags"].([]any); len(tags) != 2 || tags[0] != "eu" {
+		t.Fatalf("unexpected array attribute: %v", attributes["tags"])
+	}
+}
+
+// otlpAttributes 将 OTLP/JSON 属性列表转换为 map，便于断言
+func otlpAttributes(v any) map[string]any {
+	result := map[string]any{}
+	for _, item := range v.([]any) {
+		kv := item.(map[string]any)
+		result[kv["key"].(string)] = otlpValue(kv["value"].(map[string]any))
+	}
+	return result
+}
+
+func otlpValue(value map[string]any) any {
+	for kind, v := range value {
+		switch kind {
+		case "arrayValue":
+			var values []any
+			for _, item := range v.(map[string]any)["values"].([]any) {
+				values = append(values, otlpValue(item.(map[string]any)))
+			}
+			return values
+		case "kvlistValue":
+			return otlpAttributes(v.(map[string]any)["values"])
+		default:
+			return v
+		}
+	}
+	return nil
+}
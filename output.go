@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Format 日志输出格式
+type Format int
+
+const (
+	FormatText   Format = iota // key=value 文本格式 / Text format
+	FormatJSON                 // JSON 格式 / JSON format
+	FormatLogfmt               // logfmt 格式，Loki/Grafana 可以直接解析 / Logfmt format
+	FormatECS                  // Elastic Common Schema JSON，可以直接写入 Elasticsearch / ECS JSON
+	FormatBinary               // 紧凑的二进制格式，适合高吞吐量的文件输出，使用 binlog 包转换为 JSON 或文本 / Compact binary
+	FormatCEF                  // ArcSight Common Event Format，SIEM 安全事件 / CEF
+	FormatLEEF                 // IBM QRadar Log Event Extended Format 1.0 / LEEF
+	FormatPretty               // 开发时使用的控制台格式，列对齐、字段着色、复合类型多行显示 / Pretty console
+	FormatCustom               // 使用 WithEncoder 设置的自定义编码器 / Custom encoder
+)
+
+// String 返回格式名称
+func (f Format) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatJSON:
+		return "json"
+	case FormatLogfmt:
+		return "logfmt"
+	case FormatECS:
+		return "ecs"
+	case FormatBinary:
+		return "binary"
+	case FormatCEF:
+		return "cef"
+	case FormatLEEF:
+		return "leef"
+	case FormatPretty:
+		return "pretty"
+	case FormatCustom:
+		return "custom"
+	default:
+		return fmt.Sprintf("FORMAT(%d)", int(f))
+	}
+}
+
+// ConsoleTarget 控制台输出目标
+type ConsoleTarget int
+
+const (
+	// ConsoleDefault 保持各日志实现的默认行为：slog、logrus 输出到 stdout，zap 输出到 stderr，klog 不输出到控制台
+	ConsoleDefault ConsoleTarget = iota
+	ConsoleStdout                // 输出到标准输出 / Standard output
+	ConsoleStderr                // 输出到标准错误，适合 CLI 工具 / Standard error
+	ConsoleNone                  // 不输出到控制台，适合容器中只采集文件的场景 / Disable console output
+)
+
+// WithConsole sets the console output target
+// WithConsole 设置控制台输出目标
+// 默认 ConsoleDefault，保持各日志实现原有的控制台输出
+func WithConsole(target ConsoleTarget) Option {
+	return func(o *Options) {
+		o.Console = target
+	}
+}
+
+// OutputTarget 可以单独设置格式和颜色的日志输出
+type OutputTarget int
+
+const (
+	OutputConsole   OutputTarget = iota // 控制台 / Console
+	OutputFile                          // WithFileOutput 设置的日志文件 / Log file
+	OutputErrorFile                     // WithErrorOutPut 设置的错误日志文件 / Error log file
+	OutputRotation                      // WithLogRotation 设置的轮转文件 / Rotated log file
+)
+
+// WithConsoleFormat sets the console output format
+// WithConsoleFormat 单独设置控制台输出格式，例如控制台输出彩色文本、文件输出 JSON
+// 等同于 WithOutputFormat(OutputConsole, format)
+func WithConsoleFormat(format Format) Option {
+	return WithOutputFormat(OutputConsole, format)
+}
+
+// WithOutputFormat sets the format of a single output
+// WithOutputFormat 单独设置某个输出的格式，未设置的输出与 Format 一致
+// 同一条日志可以在控制台输出文本、在文件中输出 JSON
+func WithOutputFormat(target OutputTarget, format Format) Option {
+	return func(o *Options) {
+		if o.outputFormats == nil {
+			o.outputFormats = make(map[OutputTarget]Format)
+		}
+		o.outputFormats[target] = format
+	}
+}
+
+// WithOutputColor enables or disables color for a single output
+// WithOutputColor 单独设置某个输出是否着色
+// 默认只有开启 WithColor 时控制台着色，文件不着色；只有文本和 Pretty 格式的输出会着色，WithNativeEncoder 时 Klog 的原生文本不支持
+func WithOutputColor(target OutputTarget, enable bool) Option {
+	return func(o *Options) {
+		if o.outputColors == nil {
+			o.outputColors = make(map[OutputTarget]bool)
+		}
+		o.outputColors[target] = enable
+	}
+}
+
+// consoleOutput 返回控制台输出，ConsoleNone 返回 nil
+// defaultOutput 为日志实现在 ConsoleDefault 下的控制台输出，可以为 nil
+func consoleOutput(target ConsoleTarget, defaultOutput io.Writer) io.Writer {
+	switch target {
+	case ConsoleStdout:
+		return os.Stdout
+	case ConsoleStderr:
+		return os.Stderr
+	case ConsoleNone:
+		return nil
+	default:
+		return defaultOutput
+	}
+}
+
+// outputFormat 输出使用的格式
+func (o Options) outputFormat(target OutputTarget) Format {
+	if format, ok := o.outputFormats[target]; ok {
+		return format
+	}
+	return o.Format
+}
+
+// outputJSON 输出是否使用 JSON 格式
+func (o Options) outputJSON(target OutputTarget) bool {
+	return o.outputFormat(target) == FormatJSON
+}
+
+// outputColor 输出使用的颜色方案，不着色时返回 nil
+func (o Options) outputColor(target OutputTarget) *ColorScheme {
+	enable, ok := o.outputColors[target]
+	if !ok {
+		enable = target == OutputConsole && o.ColorEnabled
+	}
+	if format := o.outputFormat(target); !enable || (format != FormatText && format != FormatPretty) {
+		return nil
+	}
+	if o.ColorScheme == nil {
+		// 文件不是终端，使用不依赖终端检测的 ANSI 颜色
+		return DefaultANSIColorScheme
+	}
+	return o.ColorScheme
+}
+
+// formatOutputs 收集由本库基于 Record 编码的输出，未设置 WithNativeEncoder 时为所有输出
+type formatOutputs struct {
+	opts   Options
+	native func(Format) bool
+	sinks  []Sink
+	err    error
+}
+
+func newFormatOutputs(opts Options, native func(Format) bool) *formatOutputs {
+	if !opts.nativeEncoder || opts.schema != nil {
+		// 默认所有输出都由本库按统一的日志记录结构编码
+		native = func(Format) bool { return false }
+	} else if opts.multiline == MultilineIndent {
+		// slog、logrus 和 klog 原生的文本格式会转义换行，缩进的后续行由本库编码
+		libNative := native
+		native = func(format Format) bool { return format != FormatText && libNative(format) }
+	}
+	return &formatOutputs{opts: opts, native: native}
+}
+
+// add 输出由本库编码时添加基于 Record 的输出并返回 true，调用方不再创建日志库的输出
+// match 为 nil 时输出所有日志
+func (f *formatOutputs) add(format Format, match RouteMatcher, w io.Writer) bool {
+	return f.addColored(format, nil, match, w)
+}
+
+// addTarget 与 add 相同，格式和颜色使用该输出的配置
+func (f *formatOutputs) addTarget(target OutputTarget, match RouteMatcher, w io.Writer) bool {
+	return f.addColored(f.opts.outputFormat(target), f.opts.outputColor(target), match, w)
+}
+
+// addTargetFile 与 addTarget 相同，输出由本库编码时才打开文件 path，文件由该输出关闭；日志库原生输出时由调用方打开
+func (f *formatOutputs) addTargetFile(target OutputTarget, match RouteMatcher, path string) bool {
+	format := f.opts.outputFormat(target)
+	if f.native(format) {
+		return false
+	}
+	file, err := openOutput(path)
+	if err == nil {
+		var sink *routeSink
+		if sink, err = newFormatSink(f.opts, format, match, file); err == nil {
+			sink.encoder.color = f.opts.outputColor(target)
+			sink.closer = file
+			f.sinks = append(f.sinks, sink)
+			return true
+		}
+		_ = file.Close()
+	}
+	f.err = err
+	return true
+}
+
+func (f *formatOutputs) addColored(format Format, scheme *ColorScheme, match RouteMatcher, w io.Writer) bool {
+	if f.native(format) {
+		return false
+	}
+	sink, err := newFormatSink(f.opts, format, match, w)
+	if err != nil {
+		f.err = err
+		return true
+	}
+	sink.encoder.color = scheme
+	f.sinks = append(f.sinks, sink)
+	return true
+}
+
+// isTextOrJSON slog、zap、logrus 原生支持文本和 JSON 格式
+func isTextOrJSON(format Format) bool {
+	return format == FormatText || format == FormatJSON
+}
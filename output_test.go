@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout 将 os.Stdout 重定向到管道，create 中创建的日志实例会使用重定向后的 stdout
+func captureStdout(t *testing.T, create func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	create()
+	_ = w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestConsoleNone(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "app.log")
+			out := captureStdout(t, func() {
+				logger, err := NewLoggerWithType(typ, WithFileOutput(file), WithConsole(ConsoleNone))
+				if err != nil {
+					t.Fatal(err)
+				}
+				logger.Info("file only")
+			})
+			if out != "" {
+				t.Fatalf("console output = %q, want empty", out)
+			}
+			if data, _ := os.ReadFile(file); !strings.Contains(string(data), "file only") {
+				t.Fatalf("file output = %q", data)
+			}
+		})
+	}
+}
+
+func TestConsoleFormat(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		for _, native := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/native=%v", typ, native), func(t *testing.T) {
+				file := filepath.Join(t.TempDir(), "app.log")
+				out := captureStdout(t, func() {
+					opts := []Option{
+						WithFileOutput(file),
+						WithJSONFormat(),
+						WithConsole(ConsoleStdout),
+						WithConsoleFormat(FormatText),
+						WithColor(),
+						WithColorScheme(*DefaultANSIColorScheme)}
+					if native {
+						opts = append(opts, WithNativeEncoder())
+					}
+					logger, err := NewLoggerWithType(typ, opts...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					logger.Info("hello", "user", "alice")
+				})
+				// 控制台输出彩色文本，文本格式会转义控制字符
+				if !strings.Contains(out, "[32m") || !strings.Contains(out, "hello") || strings.HasPrefix(out, "{") {
+					t.Fatalf("console output = %q, want colored text", out)
+				}
+				// 文件输出不带颜色的 JSON
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var entry map[string]any
+				if err := json.Unmarshal(data, &entry); err != nil {
+					t.Fatalf("file output is not json: %q", data)
+				}
+				if strings.Contains(string(data), "\x1b[") || entry["user"] != "alice" {
+					t.Fatalf("file output = %q", data)
+				}
+			})
+		}
+	}
+}
+
+func TestOutputFormatPerOutput(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "app.log")
+			errorFile := filepath.Join(dir, "error.log")
+			logger, err := NewLoggerWithType(typ,
+				WithConsole(ConsoleNone),
+				WithFileOutput(file),
+				WithErrorOutPut(errorFile),
+				WithOutputFormat(OutputFile, FormatJSON),
+				WithOutputFormat(OutputErrorFile, FormatText),
+				WithOutputColor(OutputErrorFile, true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Error("disk full", "disk", "sda")
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var entry map[string]any
+			if err := json.Unmarshal(data, &entry); err != nil || entry["disk"] != "sda" {
+				t.Fatalf("file output = %q, want json", data)
+			}
+			data, err = os.ReadFile(errorFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(string(data), "{") || !strings.Contains(string(data), "[31m") {
+				t.Fatalf("error file output = %q, want colored text", data)
+			}
+		})
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	outputs := map[LoggerType]string{}
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithFormat(FormatLogfmt),
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.WithFields(map[string]any{"request id": "r-1"}).Info("user login", "user", map[string]any{"name": "alice"}, "ok", true)
+			logger.Debug("hidden")
+
+			got := out.String()
+			if !strings.HasPrefix(got, "time=") || strings.Count(got, "\n") != 1 {
+				t.Fatalf("output = %q", got)
+			}
+			want := `info msg="user login" request_id=r-1 user="{\"name\":\"alice\"}" ok=true`
+			_, rest, _ := strings.Cut(strings.TrimSpace(got), " level=")
+			if rest != want {
+				t.Fatalf("output = %q, want level=%s", got, want)
+			}
+			outputs[typ] = rest
+		})
+	}
+	for typ, output := range outputs {
+		if output != outputs[SlogLogger] {
+			t.Fatalf("%s output differs from slog: %q", typ, output)
+		}
+	}
+}
+
+func TestKlogJSONFormat(t *testing.T) {
+	out := &syncBuffer{}
+	logger, err := NewLoggerWithType(KlogLogger, WithJSONFormat(), WithOutput(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Warn("disk almost full", "usage", 91)
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(out.String()), &entry); err != nil {
+		t.Fatalf("output is not json: %q", out.String())
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "disk almost full" || entry["usage"] != float64(91) {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+}
+
+// openFileCount 当前进程打开 path 的文件描述符数量，不支持 /proc 时跳过
+func openFileCount(t *testing.T, path string) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip(err)
+	}
+	count := 0
+	for _, e := range entries {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", e.Name())); err == nil && target == path {
+			count++
+		}
+	}
+	return count
+}
+
+// TestOutputFilesOpenedOnce 文件和错误日志文件只打开一次，日志库原生输出时不再额外打开
+func TestOutputFilesOpenedOnce(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		for _, native := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/native=%v", typ, native), func(t *testing.T) {
+				dir := t.TempDir()
+				file := filepath.Join(dir, "app.log")
+				errorFile := filepath.Join(dir, "error.log")
+				opts := []Option{WithConsole(ConsoleNone), WithFileOutput(file), WithErrorOutPut(errorFile)}
+				if native {
+					opts = append(opts, WithNativeEncoder())
+				}
+				if _, err := NewLoggerWithType(typ, opts...); err != nil {
+					t.Fatal(err)
+				}
+				if n := openFileCount(t, file); n != 1 {
+					t.Errorf("%s opened %d times", file, n)
+				}
+				if n := openFileCount(t, errorFile); n != 1 {
+					t.Errorf("%s opened %d times", errorFile, n)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/piwriw/go-logger/internal/textenc"
+)
+
+const (
+	prettyLevelWidth   = 5  // 级别列宽度
+	prettyCallerWidth  = 20 // 调用信息列宽度
+	prettyMessageWidth = 32 // 有字段时日志内容的最小宽度，字段从同一列开始
+	prettyInlineWidth  = 40 // 复合类型编码后不超过该长度时与其他字段写在同一行
+	prettyIndent       = "    "
+)
+
+// appendRecordPretty 编码为便于开发时阅读的控制台格式
+// 时间、级别、日志名称和调用信息按列对齐，随后是日志内容和 key=value 字段；
+// 结构体、map 等复合类型格式化为多行 JSON，带堆栈的错误和多行字符串逐行缩进写在日志下方；
+// scheme 为 nil 时不着色
+func appendRecordPretty(buf *bytes.Buffer, r *Record, timestamp string, scheme *ColorScheme) {
+	var meta, key, valueColor, errorColor *Color
+	if scheme != nil {
+		meta, key, valueColor, errorColor = scheme.Meta, scheme.Key, scheme.Value, scheme.Error
+	}
+	buf.WriteString(scheme.sprint(meta, timestamp))
+	buf.WriteByte(' ')
+	level := padRight(r.Level.String(), prettyLevelWidth)
+	if scheme != nil {
+		level = scheme.Colorize(r.Level, level)
+	}
+	buf.WriteString(level)
+	if r.Logger != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(scheme.sprint(meta, "["+r.Logger+"]"))
+	}
+	if caller := r.Caller(); caller != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(scheme.sprint(meta, padRight(caller, prettyCallerWidth)))
+	}
+	buf.WriteByte(' ')
+
+	var inline []string
+	var blocks bytes.Buffer
+	message, rest, multiline := strings.Cut(r.Message, "\n")
+	if multiline {
+		writePrettyLines(&blocks, rest, prettyIndent)
+	}
+	for _, f := range r.Fields {
+		name := scheme.sprint(key, f.Key) + "="
+		value, lines := prettyValue(f.Value)
+		c := valueColor
+		if _, ok := f.Value.(error); ok {
+			c = errorColor
+		}
+		value = scheme.sprint(c, value)
+		if lines == "" {
+			inline = append(inline, name+value)
+			continue
+		}
+		// 多行的值写在日志下方
+		blocks.WriteByte('\n')
+		blocks.WriteString(prettyIndent)
+		blocks.WriteString(name)
+		blocks.WriteString(value)
+		writePrettyLines(&blocks, scheme.sprintLines(c, lines), prettyIndent)
+	}
+	if len(inline) > 0 {
+		message = padRight(message, prettyMessageWidth)
+	}
+	buf.WriteString(message)
+	for _, field := range inline {
+		buf.WriteByte(' ')
+		buf.WriteString(field)
+	}
+	buf.Write(blocks.Bytes())
+}
+
+// prettyValue 返回值的第一行和其余行，单行的值 lines 为空
+func prettyValue(v any) (string, string) {
+	switch val := v.(type) {
+	case nil:
+		return "<nil>", ""
+	case string:
+		first, rest, ok := strings.Cut(val, "\n")
+		if !ok || strings.TrimRight(rest, "\n") == "" {
+			return prettyString(val), ""
+		}
+		return first, indentLines(rest)
+	case error:
+		// 兼容 github.com/pkg/errors 等通过 %+v 输出堆栈的错误
+		if stack := fmt.Sprintf("%+v", val); stack != val.Error() {
+			first, rest, _ := strings.Cut(stack, "\n")
+			return first, indentLines(rest)
+		}
+		return prettyString(val.Error()), ""
+	case fmt.Stringer:
+		return prettyString(val.String()), ""
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return prettyString(fmt.Sprintf("%+v", v)), ""
+		}
+		if len(data) <= prettyInlineWidth {
+			return string(data), ""
+		}
+		// 多行 JSON 的右括号与 key 对齐
+		data, _ = json.MarshalIndent(v, "", "  ")
+		first, rest, _ := strings.Cut(string(data), "\n")
+		return first, rest
+	default:
+		return prettyString(fmt.Sprint(v)), ""
+	}
+}
+
+// prettyString 包含空格、等号、引号或不可打印字符的字符串加引号
+func prettyString(s string) string {
+	if textenc.NeedsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// indentLines 多行字符串和堆栈的后续行比 key 多缩进一级
+func indentLines(s string) string {
+	return prettyIndent + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prettyIndent)
+}
+
+// writePrettyLines 每行加上缩进后写在新行
+func writePrettyLines(buf *bytes.Buffer, lines, indent string) {
+	for _, line := range strings.Split(strings.TrimRight(lines, "\n"), "\n") {
+		buf.WriteByte('\n')
+		buf.WriteString(indent)
+		buf.WriteString(line)
+	}
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type prettyUser struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	Email string   `json:"email"`
+}
+
+func TestPrettyFormat(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithFormat(FormatPretty),
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("user created", "id", 7, "user", prettyUser{Name: "alice", Roles: []string{"admin", "dev"}, Email: "alice@example.com"})
+			logger.Warn("retry", "attempt", 2)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			_, first, _ := strings.Cut(lines[0], " INFO  ")
+			want := []string{
+				"user created                     id=7",
+				"    user={",
+				`      "name": "alice",`,
+				`      "roles": [`,
+				`        "admin",`,
+				`        "dev"`,
+				`      ],`,
+				`      "email": "alice@example.com"`,
+				"    }",
+			}
+			got := append([]string{first}, lines[1:len(lines)-1]...)
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Fatalf("output =\n%s", out.String())
+			}
+			if !strings.HasSuffix(lines[len(lines)-1], " WARN  retry                            attempt=2") {
+				t.Fatalf("line = %q", lines[len(lines)-1])
+			}
+		})
+	}
+}
+
+func TestPrettyRecord(t *testing.T) {
+	r := &Record{
+		Time:    time.Now(),
+		Level:   ErrorLevel,
+		Message: "query failed",
+		File:    "/app/store.go",
+		Line:    12,
+		Logger:  "db",
+		Fields: []Field{
+			{Key: "err", Value: &stackError{msg: "timeout"}},
+			{Key: "sql", Value: "SELECT *\nFROM users"},
+			{Key: "cause", Value: errors.New("conn reset")},
+		},
+	}
+	var buf bytes.Buffer
+	appendRecordPretty(&buf, r, "12:00:00", nil)
+	want := "12:00:00 ERROR [db] store.go:12          query failed                     cause=\"conn reset\"\n" +
+		"    err=timeout\n" +
+		"        main.run\n" +
+		"        \tmain.go:12\n" +
+		"    sql=SELECT *\n" +
+		"        FROM users"
+	if buf.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	appendRecordPretty(&buf, r, "12:00:00", DefaultANSIColorScheme)
+	for _, s := range []string{"\033[90m12:00:00\033[0m", "\033[31mERROR\033[0m", "\033[36mcause\033[0m=\033[31m\"conn reset\"\033[0m",
+		"\033[36msql\033[0m=\033[34mSELECT *\033[0m\n    \033[34m    FROM users\033[0m"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("output %q does not contain %q", buf.String(), s)
+		}
+	}
+}
+
+func TestPrettyConsoleColor(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.log")
+	out := captureStdout(t, func() {
+		logger, err := NewLoggerWithType(ZapLogger,
+			WithFileOutput(file),
+			WithJSONFormat(),
+			WithColor(),
+			WithConsole(ConsoleStdout),
+			WithConsoleFormat(FormatPretty))
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("started", "port", 8080)
+	})
+	if !strings.Contains(out, "\033[32mINFO \033[0m") || !strings.Contains(out, "\033[36mport\033[0m=\033[34m8080\033[0m") {
+		t.Fatalf("console output = %q", out)
+	}
+	// JSON 文件不着色
+	if data, _ := os.ReadFile(file); bytes.Contains(data, []byte("\033[")) || !bytes.Contains(data, []byte(`started","port":8080`)) {
+		t.Fatalf("file output = %q", data)
+	}
+}
@@ -0,0 +1,296 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultQueueBatchSize     = 100
+	defaultQueueFlushInterval = time.Second
+	defaultQueueSize          = 10000
+	defaultQueueSendTimeout   = 5 * time.Second
+)
+
+// Producer 消息队列生产者，例如 Kafka、Pulsar 客户端的适配
+// Send 需要保证并发安全，key 用于分区，value 为一批 NDJSON 格式的日志
+type Producer interface {
+	Send(ctx context.Context, key, value []byte) error
+}
+
+// Backpressure 队列已满时的处理方式
+type Backpressure int
+
+const (
+	BackpressureDrop  Backpressure = iota // 丢弃日志，默认，不影响业务调用
+	BackpressureBlock                     // 阻塞等待队列有空位
+)
+
+// QueueSinkConfig 消息队列输出配置
+type QueueSinkConfig struct {
+	// Producer 消息队列生产者
+	Producer Producer
+	// KeyField 分区字段，例如 tenant，相同字段值的日志使用相同的 key，字段不存在时 key 为空
+	KeyField string
+	// BatchSize 每个 key 每批最多条数，默认 100
+	BatchSize int
+	// FlushInterval 日志最长等待时间，默认 1s
+	FlushInterval time.Duration
+	// QueueSize 待发送队列长度，默认 10000
+	QueueSize int
+	// Backpressure 队列已满时的处理方式，默认丢弃
+	Backpressure Backpressure
+	// SendTimeout 单次 Send 的超时时间，默认 5s
+	SendTimeout time.Duration
+}
+
+// queueMessage 待发送的一条日志
+type queueMessage struct {
+	key  string
+	line []byte
+}
+
+// queueBatch 同一个 key 的一批日志
+type queueBatch struct {
+	buf   bytes.Buffer
+	count int
+}
+
+// QueueSink 消息队列输出端
+// 日志按 KeyField 分区、按条数和最长等待时间分批，每批以 NDJSON 格式调用一次 Producer.Send
+type QueueSink struct {
+	cfg     QueueSinkConfig
+	queue   chan queueMessage
+	flushCh chan chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	closeMu sync.RWMutex // 检查 closed 和加入队列在读锁内完成，Close 持有写锁设置 closed，保证关闭后不再有日志加入队列
+	closed  bool
+	dropped atomic.Uint64
+}
+
+var _ Sink = (*QueueSink)(nil)
+
+// NewQueueSink 创建消息队列输出端，退出前需要调用 Close 发送剩余日志
+func NewQueueSink(cfg QueueSinkConfig) (*QueueSink, error) {
+	if cfg.Producer == nil {
+		return nil, errors.New("queue sink requires producer")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultQueueBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultQueueFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = defaultQueueSendTimeout
+	}
+	s := &QueueSink{
+		cfg:     cfg,
+		queue:   make(chan queueMessage, cfg.QueueSize),
+		flushCh: make(chan chan struct{}),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// WriteRecord 将日志记录加入发送队列
+// 队列已满时按 Backpressure 丢弃并返回 ErrQueueFull，或阻塞到队列有空位
+func (s *QueueSink) WriteRecord(r *Record) error {
+	line, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	msg := queueMessage{key: s.partitionKey(r), line: append(line, '\n')}
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+	if s.cfg.Backpressure == BackpressureBlock {
+		// 后台发送在 Close 取得写锁之前会一直消费队列，持有读锁阻塞不会导致死锁
+		s.queue <- msg
+		return nil
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Flush 立即发送队列中的日志并等待完成
+func (s *QueueSink) Flush() {
+	ack := make(chan struct{})
+	select {
+	case s.flushCh <- ack:
+		<-ack
+	case <-s.done:
+	}
+}
+
+// Dropped 返回因队列已满或发送失败被丢弃的日志条数
+func (s *QueueSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 发送剩余日志并停止后台发送，Producer 的生命周期由调用方管理
+func (s *QueueSink) Close() error {
+	s.once.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		s.closeMu.Unlock()
+		close(s.closing)
+		<-s.done
+	})
+	return nil
+}
+
+// partitionKey 取分区字段的值，按 fmt.Sprint 转为字符串
+func (s *QueueSink) partitionKey(r *Record) string {
+	if s.cfg.KeyField == "" {
+		return ""
+	}
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		// 同名字段以最后一个为准，与单次调用参数覆盖持久化字段一致
+		if r.Fields[i].Key == s.cfg.KeyField {
+			return fmt.Sprint(r.Fields[i].Value)
+		}
+	}
+	return ""
+}
+
+func (s *QueueSink) run() {
+	defer close(s.done)
+	batches := make(map[string]*queueBatch)
+	timer := time.NewTimer(s.cfg.FlushInterval)
+	timer.Stop()
+	pending := 0
+
+	sendAll := func() {
+		timer.Stop()
+		for key, batch := range batches {
+			s.send(key, batch)
+		}
+		clear(batches)
+		pending = 0
+	}
+	add := func(msg queueMessage) {
+		if pending == 0 {
+			timer.Reset(s.cfg.FlushInterval)
+		}
+		batch, ok := batches[msg.key]
+		if !ok {
+			batch = &queueBatch{}
+			batches[msg.key] = batch
+		}
+		batch.buf.Write(msg.line)
+		batch.count++
+		pending++
+		// 单个 key 达到条数上限时只发送该 key 的批次
+		if batch.count >= s.cfg.BatchSize {
+			s.send(msg.key, batch)
+			delete(batches, msg.key)
+			pending -= batch.count
+			if pending == 0 {
+				timer.Stop()
+			}
+		}
+	}
+
+	for {
+		select {
+		case msg := <-s.queue:
+			add(msg)
+		case <-timer.C:
+			sendAll()
+		case ack := <-s.flushCh:
+			s.drainQueue(add)
+			sendAll()
+			close(ack)
+		case <-s.closing:
+			s.drainQueue(add)
+			sendAll()
+			return
+		}
+	}
+}
+
+func (s *QueueSink) drainQueue(add func(queueMessage)) {
+	for {
+		select {
+		case msg := <-s.queue:
+			add(msg)
+		default:
+			return
+		}
+	}
+}
+
+func (s *QueueSink) send(key string, batch *queueBatch) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.SendTimeout)
+	defer cancel()
+	var k []byte
+	if key != "" {
+		k = []byte(key)
+	}
+	if err := s.cfg.Producer.Send(ctx, k, batch.buf.Bytes()); err != nil {
+		s.dropped.Add(uint64(batch.count))
+	}
+}
+
+// ProducerMessage MemoryProducer 收到的一条消息
+type ProducerMessage struct {
+	Key   []byte
+	Value []byte
+}
+
+// MemoryProducer 内存生产者，用于测试，记录所有收到的消息
+type MemoryProducer struct {
+	mu       sync.Mutex
+	messages []ProducerMessage
+	err      error
+}
+
+var _ Producer = (*MemoryProducer)(nil)
+
+// Send 记录消息，设置了错误时返回该错误
+func (p *MemoryProducer) Send(ctx context.Context, key, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, ProducerMessage{
+		Key:   append([]byte(nil), key...),
+		Value: append([]byte(nil), value...),
+	})
+	return ctx.Err()
+}
+
+// SetError 设置 Send 返回的错误，用于模拟消息队列不可用
+func (p *MemoryProducer) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Messages 返回收到的所有消息
+func (p *MemoryProducer) Messages() []ProducerMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ProducerMessage(nil), p.messages...)
+}
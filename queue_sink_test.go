@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// decodeBatch 解析一批 NDJSON 日志
+func decodeBatch(t *testing.T, value []byte) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(value))
+	for scanner.Scan() {
+		var record map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestQueueSinkPartitionAndBatch(t *testing.T) {
+	producer := &MemoryProducer{}
+	sink, err := NewQueueSink(QueueSinkConfig{Producer: producer, KeyField: "tenant", BatchSize: 2, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLoggerWithType(SlogLogger, WithConsole(ConsoleNone), WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme := logger.WithFields(map[string]any{"tenant": "acme"})
+	acme.Info("a1")
+	logger.Info("b1", "tenant", "beta")
+	acme.Info("a2") // acme 达到 BatchSize，立即发送
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := producer.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("messages = %d, want 2", len(messages))
+	}
+	if string(messages[0].Key) != "acme" || len(decodeBatch(t, messages[0].Value)) != 2 {
+		t.Fatalf("unexpected first message: %s %s", messages[0].Key, messages[0].Value)
+	}
+	beta := decodeBatch(t, messages[1].Value)
+	if string(messages[1].Key) != "beta" || len(beta) != 1 || beta[0]["msg"] != "b1" {
+		t.Fatalf("unexpected second message: %s %s", messages[1].Key, messages[1].Value)
+	}
+}
+
+// blockingProducer 在 release 关闭前阻塞 Send，用于模拟消息队列变慢
+type blockingProducer struct {
+	MemoryProducer
+	release chan struct{}
+	once    sync.Once
+	started chan struct{}
+}
+
+func (p *blockingProducer) Send(ctx context.Context, key, value []byte) error {
+	p.once.Do(func() { close(p.started) })
+	<-p.release
+	return p.MemoryProducer.Send(ctx, key, value)
+}
+
+func TestQueueSinkBackpressureDrop(t *testing.T) {
+	producer := &blockingProducer{release: make(chan struct{}), started: make(chan struct{})}
+	sink, err := NewQueueSink(QueueSinkConfig{Producer: producer, BatchSize: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: "m"}
+	_ = sink.WriteRecord(record)
+	<-producer.started // 第一条正在发送，后台不再读取队列
+	_ = sink.WriteRecord(record)
+	if err := sink.WriteRecord(record); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+	close(producer.release)
+	_ = sink.Close()
+	if sink.Dropped() != 1 || len(producer.Messages()) != 2 {
+		t.Fatalf("dropped = %d, messages = %d", sink.Dropped(), len(producer.Messages()))
+	}
+}
+
+func TestQueueSinkSendError(t *testing.T) {
+	producer := &MemoryProducer{}
+	producer.SetError(errors.New("broker unavailable"))
+	sink, err := NewQueueSink(QueueSinkConfig{Producer: producer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sink.WriteRecord(&Record{Time: time.Now(), Level: ErrorLevel, Message: "lost"})
+	sink.Flush()
+	_ = sink.Close()
+	if sink.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want 1", sink.Dropped())
+	}
+	if err := sink.WriteRecord(&Record{}); err != ErrSinkClosed {
+		t.Fatalf("err = %v, want ErrSinkClosed", err)
+	}
+}
+
+func TestQueueSinkWriteDuringClose(t *testing.T) {
+	for _, backpressure := range []Backpressure{BackpressureDrop, BackpressureBlock} {
+		producer := &MemoryProducer{}
+		sink, err := NewQueueSink(QueueSinkConfig{Producer: producer, QueueSize: 16, FlushInterval: time.Hour, Backpressure: backpressure})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 与 Close 并发写入时，返回 nil 的日志都必须发送出去，其余返回 ErrSinkClosed 或 ErrQueueFull
+		var accepted atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					err := sink.WriteRecord(&Record{Time: time.Now(), Level: InfoLevel, Message: "m"})
+					switch {
+					case err == nil:
+						accepted.Add(1)
+					case !errors.Is(err, ErrSinkClosed) && !errors.Is(err, ErrQueueFull):
+						t.Error(err)
+					}
+				}
+			}()
+		}
+		_ = sink.Close()
+		wg.Wait()
+
+		received := 0
+		for _, message := range producer.Messages() {
+			received += len(decodeBatch(t, message.Value))
+		}
+		if int64(received) != accepted.Load() {
+			t.Fatalf("backpressure %d: received %d records, accepted %d", backpressure, received, accepted.Load())
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"runtime"
+	"time"
+)
+
+// Field 日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Record 与具体日志库无关的日志记录
+// 各日志实现在输出前构造 Record 并分发给所有 Sink
+type Record struct {
+	Time    time.Time // 日志时间
+	Level   Level     // 日志级别
+	Message string    // 日志内容，不包含颜色
+	File    string    // 调用文件完整路径，未开启 AddSource 时为空
+	Line    int       // 调用行号，未开启 AddSource 时为 0
+	Logger  string    // 日志名称，通过 WithName 设置
+	Fields  []Field   // WithFields 持久化字段和单次调用参数，已经过脱敏处理
+}
+
+// Caller 返回 file:line 形式的调用信息，未开启 AddSource 时返回空字符串
+func (r *Record) Caller() string {
+	if r.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", path.Base(r.File), r.Line)
+}
+
+// MarshalJSON 将日志记录编码为单行 JSON
+// 使用 DefaultSchema：固定字段依次为 time、level、msg、logger（设置 WithName 时）、caller（开启 AddSource 时）、
+// error（第一个 error 类型的字段），随后是其余字段
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	appendRecordJSON(&buf, r, r.Time.Format(time.RFC3339Nano), &defaultSchema)
+	return buf.Bytes(), nil
+}
+
+func appendRecordJSON(buf *bytes.Buffer, r *Record, timestamp string, schema *Schema) {
+	header, fields := schema.header(r, timestamp, r.Level.String())
+	buf.WriteByte('{')
+	writeJSONFields(buf, header)
+	if len(fields) > 0 {
+		buf.WriteByte(',')
+		if schema.FieldsKey != "" {
+			writeJSONValue(buf, schema.FieldsKey)
+			buf.WriteString(":{")
+			writeJSONFields(buf, fields)
+			buf.WriteByte('}')
+		} else {
+			writeJSONFields(buf, fields)
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// writeJSONFields 写入逗号分隔的 "key":value
+func writeJSONFields(buf *bytes.Buffer, fields []Field) {
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONValue(buf, f.Key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, f.Value)
+	}
+}
+
+// writeJSONValue 写入 JSON 值，error 使用 Error() 文本，无法编码的值使用 fmt.Sprint 文本
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	if err, ok := v.(error); ok {
+		if _, isMarshaler := v.(json.Marshaler); !isMarshaler {
+			v = err.Error()
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+// Sink 日志记录输出端
+// 与 io.Writer 形式的输出不同，Sink 接收结构化的 Record，可以自行决定编码方式
+type Sink interface {
+	// WriteRecord 写入一条日志记录，实现需要保证并发安全
+	WriteRecord(r *Record) error
+	// Close 刷新缓冲并释放资源
+	Close() error
+}
+
+// sinkBuilder 在所有配置项应用后创建 Sink，便于在创建日志实例时统一返回错误
+type sinkBuilder func(opts Options) (Sink, error)
+
+// WithSink 添加自定义日志输出端，所有日志实现都会把日志记录分发到该输出端
+// Sink 的生命周期由调用方管理，退出前需要调用 Close；创建日志实例失败时也不会关闭该输出端
+func WithSink(sink Sink) Option {
+	return func(o *Options) {
+		o.sinkBuilders = append(o.sinkBuilders, func(Options) (Sink, error) {
+			return callerSink{sink}, nil
+		})
+	}
+}
+
+// callerSink 由调用方持有的 Sink，日志实例不负责关闭
+type callerSink struct {
+	Sink
+}
+
+func (callerSink) Close() error {
+	return nil
+}
+
+// newSinks 根据配置创建所有 Sink
+func newSinks(opts Options) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(opts.sinkBuilders))
+	for _, build := range opts.sinkBuilders {
+		sink, err := build(opts)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// closeSinks 创建日志实例失败时关闭已经创建的 Sink
+func closeSinks(sinks []Sink) {
+	for _, s := range sinks {
+		_ = s.Close()
+	}
+}
+
+// newRecord 构造日志记录
+// callNum 与 getCaller 含义一致，fields 为持久化字段，args 为已经脱敏的单次调用参数
+func newRecord(name string, level Level, msg string, addSource bool, callNum int, fields []any, args []any) *Record {
+	r := &Record{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Logger:  name,
+		Fields:  make([]Field, 0, (len(fields)+len(args)+1)/2),
+	}
+	if addSource {
+		if _, file, line, ok := runtime.Caller(callNum); ok {
+			r.File, r.Line = file, line
+		}
+	}
+	r.Fields = appendFields(r.Fields, fields)
+	r.Fields = appendFields(r.Fields, args)
+	return r
+}
+
+// appendFields 将 KV 参数追加为 Field，非 string 类型的 key 会被忽略，缺失的值记为 !MISSING!
+func appendFields(dst []Field, args []any) []Field {
+	for i := 0; i < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			continue
+		}
+		var value any = "!MISSING!"
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+		dst = append(dst, Field{Key: key, Value: value})
+	}
+	return dst
+}
+
+// LevelEnabler 可选接口，Sink 实现后由 Sink 自行决定接收哪些级别的日志
+// 低于日志实例级别的日志也会交给 Enabled 判断，例如 RingBufferSink 需要保留 Debug 日志
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+// sinkEnabled 判断 Sink 是否接收该级别的日志，enabled 为日志实例是否启用该级别
+func sinkEnabled(sink Sink, level Level, enabled bool) bool {
+	if e, ok := sink.(LevelEnabler); ok {
+		return e.Enabled(level)
+	}
+	return enabled
+}
+
+// sinksEnabled 判断是否有 Sink 接收该级别的日志，用于在构造 Record 前快速判断
+func sinksEnabled(sinks []Sink, level Level, enabled bool) bool {
+	for _, sink := range sinks {
+		if sinkEnabled(sink, level, enabled) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSinks 将日志记录分发到接收该级别的 Sink
+// 与各日志库的 Handler 一致，输出错误不会影响业务调用
+func writeSinks(sinks []Sink, enabled bool, r *Record) {
+	for _, sink := range sinks {
+		if sinkEnabled(sink, r.Level, enabled) {
+			_ = sink.WriteRecord(r)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultRingBufferSize = 1000
+
+// RingBufferConfig 内存环形缓冲配置
+type RingBufferConfig struct {
+	// Size 保留的最近日志条数，默认 1000
+	Size int
+	// Output 转储输出，为空时使用 WithErrorOutPut 设置的文件，未设置错误日志文件时使用 stderr
+	Output io.Writer
+	// DumpLevel 达到该级别的日志会触发自动转储，为 nil 时使用 ErrorLevel
+	DumpLevel *Level
+	// DisableAutoDump 关闭自动转储，只通过 Dump 手动转储
+	DisableAutoDump bool
+}
+
+// RingBufferSink 在内存中保留最近 N 条日志（飞行记录器）
+// 包括低于日志级别的 Debug 日志，出现 Error/Fatal 日志时或调用 Dump 时转储到错误输出，
+// 无需一直把 Debug 日志写入磁盘也能看到故障前后的上下文
+type RingBufferSink struct {
+	cfg       RingBufferConfig
+	dumpLevel Level
+	mu        sync.Mutex
+	records   []ringBufferEntry
+	next      int  // 下一条记录写入的位置
+	full      bool // 缓冲是否已写满一轮
+	target    *ringBufferTarget
+}
+
+// ringBufferEntry 缓冲中的一条日志以及写入它的日志实例的转储配置
+type ringBufferEntry struct {
+	record *Record
+	target *ringBufferTarget
+}
+
+// ringBufferTarget 日志实例写入环形缓冲时使用的 Sink
+// 每个日志实例按自己的 Format、TimeFormat、TimeZone 和错误输出转储，多个日志实例共享同一个缓冲时互不影响
+type ringBufferTarget struct {
+	sink    *RingBufferSink
+	encoder *recordEncoder
+	output  io.Writer
+	path    string // 错误日志文件，转储时打开，写入后关闭
+}
+
+var (
+	_ Sink         = (*RingBufferSink)(nil)
+	_ LevelEnabler = (*RingBufferSink)(nil)
+	_ Sink         = (*ringBufferTarget)(nil)
+	_ LevelEnabler = (*ringBufferTarget)(nil)
+)
+
+// NewRingBufferSink 创建内存环形缓冲
+// 通过 WithRingBuffer 添加到日志实例后，每条日志按写入它的日志实例的 Format、TimeFormat、TimeZone 转储
+func NewRingBufferSink(cfg RingBufferConfig) *RingBufferSink {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultRingBufferSize
+	}
+	s := &RingBufferSink{
+		cfg:       cfg,
+		dumpLevel: ErrorLevel,
+		records:   make([]ringBufferEntry, cfg.Size),
+	}
+	if cfg.DumpLevel != nil {
+		s.dumpLevel = *cfg.DumpLevel
+	}
+	s.target = &ringBufferTarget{
+		sink:    s,
+		encoder: &recordEncoder{timeFormat: defaultTimeFormat, location: time.Local},
+		output:  cfg.Output,
+	}
+	return s
+}
+
+// WithRingBuffer 添加内存环形缓冲，所有级别的日志都会写入缓冲，不受日志级别限制
+// 同一个 RingBufferSink 可以添加到多个日志实例，缓冲共享，各日志实例的转储格式和输出互不影响
+func WithRingBuffer(sink *RingBufferSink) Option {
+	return func(o *Options) {
+		o.sinkBuilders = append(o.sinkBuilders, func(opts Options) (Sink, error) {
+			encoder, err := newRecordEncoder(opts)
+			if err != nil {
+				return nil, err
+			}
+			if encoder.format == FormatBinary {
+				// 转储带有文本标记，二进制格式使用文本转储
+				encoder.format = FormatText
+			}
+			target := &ringBufferTarget{sink: sink, encoder: encoder, output: sink.cfg.Output}
+			if target.output == nil {
+				target.output = os.Stderr
+				target.path = opts.ErrorOutput
+			}
+			return target, nil
+		})
+	}
+}
+
+// Enabled 接收所有级别的日志
+func (t *ringBufferTarget) Enabled(Level) bool {
+	return true
+}
+
+func (t *ringBufferTarget) WriteRecord(r *Record) error {
+	return t.sink.write(r, t)
+}
+
+// Close 缓冲由多个日志实例共享，不随单个日志实例关闭，需要时调用 RingBufferSink.Close
+func (t *ringBufferTarget) Close() error {
+	return nil
+}
+
+// Enabled 接收所有级别的日志
+func (s *RingBufferSink) Enabled(Level) bool {
+	return true
+}
+
+// WriteRecord 写入缓冲，达到 DumpLevel 时转储并清空缓冲
+func (s *RingBufferSink) WriteRecord(r *Record) error {
+	return s.write(r, s.target)
+}
+
+func (s *RingBufferSink) write(r *Record, target *ringBufferTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[s.next] = ringBufferEntry{record: r, target: target}
+	s.next = (s.next + 1) % len(s.records)
+	if s.next == 0 {
+		s.full = true
+	}
+	if s.cfg.DisableAutoDump || r.Level < s.dumpLevel {
+		return nil
+	}
+	return s.dumpTargetLocked(target)
+}
+
+// Records 返回缓冲中的日志，按时间从旧到新排列
+func (s *RingBufferSink) Records() []*Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.snapshotLocked()
+	records := make([]*Record, len(entries))
+	for i, entry := range entries {
+		records[i] = entry.record
+	}
+	return records
+}
+
+// Dump 将缓冲中的日志转储到输出并清空缓冲
+// 输出为 RingBufferConfig.Output，未设置时使用最近一条日志所属日志实例的错误输出
+func (s *RingBufferSink) Dump() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.snapshotLocked()
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.dumpTargetLocked(entries[len(entries)-1].target)
+}
+
+// DumpTo 将缓冲中的日志转储到 w 并清空缓冲
+func (s *RingBufferSink) DumpTo(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dumpLocked(w)
+}
+
+// Close 清空缓冲
+func (s *RingBufferSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLocked()
+	return nil
+}
+
+func (s *RingBufferSink) snapshotLocked() []ringBufferEntry {
+	var records []ringBufferEntry
+	if s.full {
+		records = append(records, s.records[s.next:]...)
+	}
+	return append(records, s.records[:s.next]...)
+}
+
+func (s *RingBufferSink) resetLocked() {
+	clear(s.records)
+	s.next = 0
+	s.full = false
+}
+
+// dumpTargetLocked 转储到日志实例的错误输出，错误日志文件只在转储时打开
+func (s *RingBufferSink) dumpTargetLocked(target *ringBufferTarget) error {
+	if target.path == "" {
+		return s.dumpLocked(target.output)
+	}
+	file, err := openOutput(target.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.dumpLocked(file)
+}
+
+// dumpLocked 转储格式：开始标记、每条日志一行、结束标记
+func (s *RingBufferSink) dumpLocked(w io.Writer) error {
+	if w == nil {
+		w = os.Stderr
+	}
+	records := s.snapshotLocked()
+	if len(records) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "----- ring buffer dump: %d records -----\n", len(records))
+	for _, entry := range records {
+		data, err := entry.target.encoder.encode(entry.record)
+		if err != nil {
+			// 单条日志编码失败不影响其他日志的转储
+			fmt.Fprintf(&buf, "encode record failed: %v\n", err)
+			continue
+		}
+		buf.Write(data)
+	}
+	buf.WriteString("----- end of ring buffer dump -----\n")
+	s.resetLocked()
+	_, err := w.Write(buf.Bytes())
+	return err
+}
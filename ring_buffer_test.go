@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRingBufferDumpOnError(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			ring := NewRingBufferSink(RingBufferConfig{Size: 10, Output: out})
+			logger, err := NewLoggerWithType(typ,
+				WithLevel(InfoLevel),
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithRingBuffer(ring))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Debug("cache miss", "key", "user:1")
+			logger.Info("request started")
+			if out.String() != "" {
+				t.Fatalf("dumped before error: %q", out.String())
+			}
+			logger.Error("request failed")
+
+			dump := out.String()
+			for _, want := range []string{"ring buffer dump: 3 records", "cache miss", "key=user:1", "request started", "request failed"} {
+				if !strings.Contains(dump, want) {
+					t.Fatalf("dump missing %q: %q", want, dump)
+				}
+			}
+			if records := ring.Records(); len(records) != 0 {
+				t.Fatalf("buffer not cleared after dump: %d records", len(records))
+			}
+		})
+	}
+}
+
+func TestRingBufferWrapAround(t *testing.T) {
+	ring := NewRingBufferSink(RingBufferConfig{Size: 3, DisableAutoDump: true})
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		_ = ring.WriteRecord(&Record{Time: time.Now(), Level: ErrorLevel, Message: msg})
+	}
+	var got []string
+	for _, r := range ring.Records() {
+		got = append(got, r.Message)
+	}
+	if strings.Join(got, ",") != "c,d,e" {
+		t.Fatalf("records = %v, want [c d e]", got)
+	}
+
+	var buf bytes.Buffer
+	if err := ring.DumpTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 5 || !strings.Contains(lines[1], "msg=c") {
+		t.Fatalf("dump = %q", buf.String())
+	}
+}
+
+func TestRingBufferSharedByLoggers(t *testing.T) {
+	dir := t.TempDir()
+	ring := NewRingBufferSink(RingBufferConfig{Size: 10})
+	jsonLogger, err := NewLoggerWithType(SlogLogger,
+		WithFormat(FormatJSON),
+		WithConsole(ConsoleNone),
+		WithErrorOutPut(filepath.Join(dir, "json_error.log")),
+		WithRingBuffer(ring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	textLogger, err := NewLoggerWithType(SlogLogger,
+		WithConsole(ConsoleNone),
+		WithErrorOutPut(filepath.Join(dir, "text_error.log")),
+		WithRingBuffer(ring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 后创建的日志实例不会修改先创建的日志实例的转储格式和输出
+	jsonLogger.Debug("first")
+	textLogger.Debug("second")
+	jsonLogger.Error("failed")
+
+	data, err := os.ReadFile(filepath.Join(dir, "json_error.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := string(data)
+	for _, want := range []string{`"msg":"first"`, "msg=second", `"msg":"failed"`} {
+		if !strings.Contains(dump, want) {
+			t.Fatalf("dump missing %q: %q", want, dump)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "text_error.log")); strings.Contains(string(data), "ring buffer dump") {
+		t.Fatalf("dump written to another logger's output: %q", data)
+	}
+}
+
+func TestRingBufferDumpLevelDebug(t *testing.T) {
+	out := &syncBuffer{}
+	level := DebugLevel
+	ring := NewRingBufferSink(RingBufferConfig{Output: out, DumpLevel: &level})
+	if err := ring.WriteRecord(newRecord("", DebugLevel, "probe", false, 1, nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "msg=probe") {
+		t.Fatalf("debug record should trigger dump: %q", out.String())
+	}
+}
+
+// TestRingBufferKeptOnFailedLogger 创建日志实例失败时不会清空其他日志实例共享的缓冲
+func TestRingBufferKeptOnFailedLogger(t *testing.T) {
+	ring := NewRingBufferSink(RingBufferConfig{Size: 10, DisableAutoDump: true})
+	logger, err := NewLoggerWithType(SlogLogger, WithConsole(ConsoleNone), WithRingBuffer(ring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("kept")
+	_, err = NewLoggerWithType(SlogLogger,
+		WithConsole(ConsoleNone),
+		WithRingBuffer(ring),
+		WithLevelOutput(WarnLevel, filepath.Join(t.TempDir(), "missing", "warn.log")))
+	if err == nil {
+		t.Fatal("expected error for unwritable level output")
+	}
+	if records := ring.Records(); len(records) != 1 || records[0].Message != "kept" {
+		t.Fatalf("records = %v", records)
+	}
+}
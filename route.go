@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RouteMatcher 路由匹配函数，返回 true 时日志记录会写入对应输出
+type RouteMatcher func(r *Record) bool
+
+// WithLevelOutput 将指定级别的日志额外写入文件
+// 例如 WithLevelOutput(WarnLevel, "warn.log") 只会写入 Warn 级别的日志，
+// 与 WithErrorOutPut 可以同时使用，格式与 Format、TimeFormat、TimeZone 配置一致
+// 文件无法打开时创建日志实例返回错误
+func WithLevelOutput(level Level, path string) Option {
+	return func(o *Options) {
+		o.sinkBuilders = append(o.sinkBuilders, func(opts Options) (Sink, error) {
+			file, err := openOutput(path)
+			if err != nil {
+				return nil, err
+			}
+			sink, err := newRouteSink(opts, MatchLevel(level), file)
+			if err != nil {
+				_ = file.Close()
+				return nil, err
+			}
+			sink.closer = file
+			return sink, nil
+		})
+	}
+}
+
+// WithRoute 将匹配的日志记录额外写入 w
+// 格式与 Format、TimeFormat、TimeZone 配置一致，所有日志实现的输出格式相同
+func WithRoute(match RouteMatcher, w io.Writer) Option {
+	return func(o *Options) {
+		o.sinkBuilders = append(o.sinkBuilders, func(opts Options) (Sink, error) {
+			return newRouteSink(opts, match, w)
+		})
+	}
+}
+
+// MatchLevel 匹配指定级别的日志
+func MatchLevel(levels ...Level) RouteMatcher {
+	return func(r *Record) bool {
+		for _, level := range levels {
+			if r.Level == level {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchMinLevel 匹配不低于指定级别的日志
+func MatchMinLevel(level Level) RouteMatcher {
+	return func(r *Record) bool {
+		return r.Level >= level
+	}
+}
+
+// MatchField 匹配字段值，值按 fmt.Sprint 的结果比较
+func MatchField(key string, value any) RouteMatcher {
+	want := fmt.Sprint(value)
+	return func(r *Record) bool {
+		for _, f := range r.Fields {
+			if f.Key == key && fmt.Sprint(f.Value) == want {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchLogger 匹配通过 WithName 设置的日志名称
+func MatchLogger(name string) RouteMatcher {
+	return func(r *Record) bool {
+		return r.Logger == name
+	}
+}
+
+// routeSink 将匹配的日志记录编码后写入 io.Writer
+type routeSink struct {
+	match   RouteMatcher
+	encoder *recordEncoder
+	mu      sync.Mutex
+	writer  io.Writer
+	closer  io.Closer // 由 Sink 打开的文件，WithRoute 等由调用方提供的输出为 nil
+}
+
+var _ Sink = (*routeSink)(nil)
+
+func newRouteSink(opts Options, match RouteMatcher, w io.Writer) (*routeSink, error) {
+	return newFormatSink(opts, opts.Format, match, w)
+}
+
+// newFormatSink 使用指定格式编码的输出
+// 由本库编码的输出（默认为所有输出，WithNativeEncoder 时为日志库不支持的格式）通过该 Sink 输出，所有日志实现的输出相同
+func newFormatSink(opts Options, format Format, match RouteMatcher, w io.Writer) (*routeSink, error) {
+	encoder, err := newRecordEncoder(opts)
+	if err != nil {
+		return nil, err
+	}
+	encoder.format = format
+	return &routeSink{match: match, encoder: encoder, writer: w}, nil
+}
+
+func (s *routeSink) WriteRecord(r *Record) error {
+	if s.match != nil && !s.match(r) {
+		return nil
+	}
+	data, err := s.encoder.encode(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(data)
+	return err
+}
+
+// Close 关闭 Sink 打开的文件，调用方提供的输出由调用方关闭
+func (s *routeSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closer.Close()
+}
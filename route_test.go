@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer 并发安全的 bytes.Buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// stripTime 去掉每行开头的 time 字段，便于比较不同日志实现的输出
+func stripTime(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		if _, rest, ok := strings.Cut(line, " level="); ok {
+			lines[i] = "level=" + rest
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRoutes(t *testing.T) {
+	outputs := map[LoggerType][3]string{}
+	for _, loggerType := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(loggerType), func(t *testing.T) {
+			dir := t.TempDir()
+			warnPath := filepath.Join(dir, "warn.log")
+			tenantOutput := &syncBuffer{}
+			nameOutput := &syncBuffer{}
+			logger, err := NewLoggerWithType(loggerType,
+				WithName("billing"),
+				WithFileOutput(filepath.Join(dir, "app.log")),
+				WithLevelOutput(WarnLevel, warnPath),
+				WithRoute(MatchField("tenant", "acme"), tenantOutput),
+				WithRoute(MatchLogger("billing"), nameOutput),
+				WithMark())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tenant := logger.WithFields(map[string]any{"tenant": "acme"})
+			tenant.Info("invoice created", "password", "secret")
+			tenant.Warn("invoice overdue", "days", 3)
+			logger.Warn("quota low")
+			logger.Error("payment failed")
+
+			warn, err := os.ReadFile(warnPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Count(string(warn), "\n") != 2 || !strings.Contains(string(warn), `msg="invoice overdue"`) || !strings.Contains(string(warn), `msg="quota low"`) {
+				t.Fatalf("unexpected warn output:\n%s", warn)
+			}
+			if strings.Count(tenantOutput.String(), "\n") != 2 || strings.Contains(tenantOutput.String(), "secret") {
+				t.Fatalf("unexpected tenant output:\n%s", tenantOutput)
+			}
+			if strings.Count(nameOutput.String(), "\n") != 4 || !strings.Contains(nameOutput.String(), "logger=billing") {
+				t.Fatalf("unexpected logger output:\n%s", nameOutput)
+			}
+			outputs[loggerType] = [3]string{stripTime(string(warn)), stripTime(tenantOutput.String()), stripTime(nameOutput.String())}
+		})
+	}
+	// 所有日志实现的路由输出一致
+	for loggerType, output := range outputs {
+		if output != outputs[SlogLogger] {
+			t.Fatalf("%s output differs from slog:\n%v\n%v", loggerType, output, outputs[SlogLogger])
+		}
+	}
+}
+
+func TestLevelOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewLoggerWithType(SlogLogger, WithConsole(ConsoleNone), WithLevelOutput(WarnLevel, filepath.Join(dir, "missing", "warn.log"))); err == nil {
+		t.Fatal("expected error for unwritable level output")
+	}
+
+	path := filepath.Join(dir, "warn.log")
+	var opts Options
+	WithLevelOutput(WarnLevel, path)(&opts)
+	sink, err := opts.sinkBuilders[0](opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := openFileCount(t, path); n != 1 {
+		t.Fatalf("%s opened %d times", path, n)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := openFileCount(t, path); n != 0 {
+		t.Fatalf("%s still open after Close", path)
+	}
+}
+
+// closeCountSink 记录 Close 调用次数
+type closeCountSink struct {
+	closed int
+}
+
+func (s *closeCountSink) WriteRecord(*Record) error { return nil }
+func (s *closeCountSink) Close() error {
+	s.closed++
+	return nil
+}
+
+// TestWithSinkNotClosed 调用方持有的 Sink 在创建日志实例失败时不会被关闭
+func TestWithSinkNotClosed(t *testing.T) {
+	sink := &closeCountSink{}
+	_, err := NewLoggerWithType(SlogLogger,
+		WithConsole(ConsoleNone),
+		WithSink(sink),
+		WithLevelOutput(WarnLevel, filepath.Join(t.TempDir(), "missing", "warn.log")))
+	if err == nil {
+		t.Fatal("expected error for unwritable level output")
+	}
+	if sink.closed != 0 {
+		t.Fatalf("caller sink closed %d times", sink.closed)
+	}
+}
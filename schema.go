@@ -0,0 +1,110 @@
+package logger
+
+// Schema 与日志库无关的日志记录结构，定义时间、级别、日志内容、调用信息、日志名称、错误和字段使用的 key
+type Schema struct {
+	TimeKey    string // 时间，默认 time
+	LevelKey   string // 级别，默认 level
+	MessageKey string // 日志内容，默认 msg
+	CallerKey  string // 调用信息 file:line，默认 caller
+	LoggerKey  string // 日志名称，默认 logger
+	// ErrorKey 第一个 error 类型的字段写入该 key，默认 error，为空时 error 字段与其他字段相同
+	ErrorKey string
+	// FieldsKey 用户字段所在的对象，为空时写在顶层；文本和 logfmt 格式中作为 key 的前缀，例如 fields.user
+	FieldsKey string
+
+	levelFormat LevelFormat // WithLevelFormat 设置的级别写法
+}
+
+// DefaultSchema 返回默认的日志记录结构，未设置 WithSchema 时所有日志实现、Sink 和 Record.MarshalJSON 都使用该结构
+func DefaultSchema() Schema {
+	return defaultSchema
+}
+
+// defaultSchema 默认的日志记录结构
+var defaultSchema = Schema{
+	TimeKey:    "time",
+	LevelKey:   "level",
+	MessageKey: "msg",
+	CallerKey:  "caller",
+	LoggerKey:  "logger",
+	ErrorKey:   "error",
+}
+
+// WithSchema sets a backend-independent record schema
+// WithSchema 修改日志记录结构的 key，未设置的时间、级别、日志内容、调用信息和日志名称的 key 使用 DefaultSchema 中的值
+// 日志记录默认由本库编码，四种日志实现的输出完全一致；同时设置 WithNativeEncoder 时仍由本库编码
+func WithSchema(schema Schema) Option {
+	return func(o *Options) {
+		def := DefaultSchema()
+		if schema.TimeKey == "" {
+			schema.TimeKey = def.TimeKey
+		}
+		if schema.LevelKey == "" {
+			schema.LevelKey = def.LevelKey
+		}
+		if schema.MessageKey == "" {
+			schema.MessageKey = def.MessageKey
+		}
+		if schema.CallerKey == "" {
+			schema.CallerKey = def.CallerKey
+		}
+		if schema.LoggerKey == "" {
+			schema.LoggerKey = def.LoggerKey
+		}
+		o.schema = &schema
+	}
+}
+
+// WithNativeEncoder uses each backend's own encoder for text and JSON output
+// WithNativeEncoder 文本和 JSON 输出改用各日志库原生的编码器，例如需要 zap 的编码性能时
+// JSON 输出同样使用 time、level、msg、caller、logger 和字段，但 error 字段不会移动到 error，key 的顺序以及文本格式的排版由各日志库决定
+// 日志库不支持的格式和设置了 WithSchema 时仍由本库编码
+func WithNativeEncoder() Option {
+	return func(o *Options) {
+		o.nativeEncoder = true
+	}
+}
+
+// header 按 Schema 展开日志记录的固定部分：时间、级别、日志内容、日志名称、调用信息和错误，返回剩余的字段
+// level 为未设置级别写法时使用的值
+func (s *Schema) header(r *Record, timestamp, level string) ([]Field, []Field) {
+	header := make([]Field, 0, 6)
+	header = append(header,
+		Field{Key: s.TimeKey, Value: timestamp},
+		Field{Key: s.LevelKey, Value: formatLevel(r.Level, s.levelFormat, level)},
+		Field{Key: s.MessageKey, Value: r.Message})
+	if r.Logger != "" {
+		header = append(header, Field{Key: s.LoggerKey, Value: r.Logger})
+	}
+	if caller := r.Caller(); caller != "" {
+		header = append(header, Field{Key: s.CallerKey, Value: caller})
+	}
+	fields := r.Fields
+	if s.ErrorKey != "" {
+		for i, f := range fields {
+			if err, ok := f.Value.(error); ok {
+				header = append(header, Field{Key: s.ErrorKey, Value: err.Error()})
+				fields = append(fields[:i:i], fields[i+1:]...)
+				break
+			}
+		}
+	}
+	return header, fields
+}
+
+// fieldKey 文本和 logfmt 格式中用户字段的 key
+func (s *Schema) fieldKey(key string) string {
+	if s.FieldsKey == "" {
+		return key
+	}
+	return s.FieldsKey + "." + key
+}
+
+// recordSchema 基于 Record 的输出使用的结构，包含 WithFieldKeys 和 WithLevelFormat 的设置
+func (o Options) recordSchema() *Schema {
+	schema := &defaultSchema
+	if o.schema != nil {
+		schema = o.schema
+	}
+	return recordKeysSchema(schema, o.fieldKeys, o.levelFormat)
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// emitSchemaRecords 所有日志实现使用相同的调用位置，保证 caller 一致
+func emitSchemaRecords(logger Logger) {
+	logger.Debug("hidden")
+	logger.Info("user login", "user", "alice", "attempt", 2)
+	logger.WithFields(map[string]any{"tenant": "acme"}).Warn("quota low", "usage", 0.93)
+	logger.Error("payment failed", "err", errors.New("card declined"), "order_id", 42)
+}
+
+func TestSchemaConformance(t *testing.T) {
+	defaultSchema := DefaultSchema()
+	schemas := []struct {
+		name   string
+		format Format
+		schema *Schema // nil 时不设置 WithSchema
+	}{
+		{"default-json", FormatJSON, nil},
+		{"default-text", FormatText, nil},
+		{"json", FormatJSON, &defaultSchema},
+		{"text", FormatText, &defaultSchema},
+		{"logfmt", FormatLogfmt, &defaultSchema},
+		{"json-custom-keys", FormatJSON, &Schema{TimeKey: "ts", LevelKey: "severity", MessageKey: "message", CallerKey: "src", ErrorKey: "err", FieldsKey: "fields"}},
+	}
+	var got strings.Builder
+	for _, s := range schemas {
+		outputs := map[LoggerType]string{}
+		for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+			out := &syncBuffer{}
+			opts := []Option{
+				WithFormat(s.format),
+				WithName("api"),
+				WithAddSource(),
+				WithTimeFormat("TIME"), // 固定时间，便于与 golden 文件比较
+				WithConsole(ConsoleNone),
+				WithOutput(out)}
+			if s.schema != nil {
+				opts = append(opts, WithSchema(*s.schema))
+			}
+			logger, err := NewLoggerWithType(typ, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			emitSchemaRecords(logger)
+			outputs[typ] = out.String()
+		}
+		for typ, output := range outputs {
+			if output != outputs[SlogLogger] {
+				t.Errorf("%s: %s output differs from slog:\n%s\n%s", s.name, typ, output, outputs[SlogLogger])
+			}
+		}
+		got.WriteString("# " + s.name + "\n" + outputs[SlogLogger])
+	}
+
+	golden := filepath.Join("testdata", "schema.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(got.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != string(want) {
+		t.Fatalf("output differs from %s, run go test -run TestSchemaConformance -update to update:\n%s", golden, got.String())
+	}
+}
+
+// TestSchemaConformanceNative WithNativeEncoder 时各日志实现使用原生编码器，
+// JSON 输出的 key 和值与 DefaultSchema 一致（error 字段除外），key 的顺序由各日志库决定，按解析后的对象比较
+// klog 原生只支持文本格式，JSON 仍由本库编码
+func TestSchemaConformanceNative(t *testing.T) {
+	var want []map[string]any
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger} {
+		out := &syncBuffer{}
+		logger, err := NewLoggerWithType(typ,
+			WithFormat(FormatJSON),
+			WithNativeEncoder(),
+			WithName("api"),
+			WithAddSource(),
+			WithTimeFormat("TIME"),
+			WithConsole(ConsoleNone),
+			WithOutput(out))
+		if err != nil {
+			t.Fatal(err)
+		}
+		emitSchemaRecords(logger)
+		got := decodeJSONLines(t, out.String())
+		if want == nil {
+			want = got
+			schema := DefaultSchema()
+			for _, key := range []string{schema.TimeKey, schema.LevelKey, schema.MessageKey, schema.CallerKey, schema.LoggerKey} {
+				if _, ok := want[0][key]; !ok {
+					t.Fatalf("%s output has no %q: %s", typ, key, out.String())
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s output differs from slog:\n%s\n%v", typ, out.String(), want)
+		}
+	}
+}
+
+func decodeJSONLines(t *testing.T, s string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid json %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// TestNativeTextRecord 原生文本格式不在日志内容中拼接调用信息和时间
+func TestNativeTextRecord(t *testing.T) {
+	for _, typ := range []LoggerType{ZapLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			out := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ, WithNativeEncoder(), WithTimeFormat("TIME"), WithConsole(ConsoleNone), WithOutput(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("user login", "user", "alice")
+			got := out.String()
+			if strings.Contains(got, "caller") || strings.Contains(got, "[20") || !strings.Contains(got, "user login") {
+				t.Fatalf("output = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSecurityVendor   = "piwriw"
+	defaultSecurityProduct  = "go-logger"
+	defaultSecurityVersion  = "1.0"
+	defaultSecurityEventKey = "event"
+	// leefTimeFormat LEEF devTime 的格式，与 devTimeFormat 中的 Java 格式 MMM dd yyyy HH:mm:ss.SSS z 对应
+	leefTimeFormat = "Jan 02 2006 15:04:05.000 MST"
+)
+
+// SecurityEventConfig CEF 和 LEEF 安全事件格式的配置
+type SecurityEventConfig struct {
+	// Vendor 设备厂商，默认 piwriw
+	Vendor string
+	// Product 产品名称，默认 go-logger
+	Product string
+	// Version 产品版本，默认 1.0
+	Version string
+	// EventKey 事件 ID 所在的字段，例如 event=login，默认 event
+	// 写入 CEF 的 Signature ID 和 LEEF 的 EventID，字段不存在时使用日志内容
+	EventKey string
+	// FieldMapping 字段名到 CEF/LEEF 字段名的映射，例如 user→suser、ip→src，未映射的字段使用原字段名
+	FieldMapping map[string]string
+	// Severity 日志级别到严重程度（0-10）的映射，默认 SecuritySeverity
+	Severity func(Level) int
+}
+
+// WithSecurityEvent sets the configuration of CEF and LEEF output
+// WithSecurityEvent 设置 CEF 和 LEEF 格式的厂商、产品、事件 ID 字段和字段映射
+// 字段在编码前已经过脱敏处理，审计日志中的密码、手机号等字段同样按脱敏规则输出
+func WithSecurityEvent(cfg SecurityEventConfig) Option {
+	return func(o *Options) {
+		o.securityEvent = cfg
+	}
+}
+
+// WithSecurityEventOutput 将安全事件（包含 EventKey 字段的日志，例如登录、权限变更）以 CEF 或 LEEF 格式额外写入 w
+// 例如 logger.Info("user login", "event", "login", "user", "alice")
+func WithSecurityEventOutput(format Format, w io.Writer) Option {
+	return func(o *Options) {
+		o.sinkBuilders = append(o.sinkBuilders, func(opts Options) (Sink, error) {
+			return newFormatSink(opts, format, MatchFieldExists(opts.securityEventConfig().EventKey), w)
+		})
+	}
+}
+
+// MatchFieldExists 匹配包含指定字段的日志
+func MatchFieldExists(key string) RouteMatcher {
+	return func(r *Record) bool {
+		for _, f := range r.Fields {
+			if f.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SecuritySeverity 默认的严重程度映射：Debug 1、Info 3、Warn 6、Error 8、Fatal 10
+func SecuritySeverity(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 1
+	case InfoLevel:
+		return 3
+	case WarnLevel:
+		return 6
+	case ErrorLevel:
+		return 8
+	case FatalLevel:
+		return 10
+	default:
+		return 5
+	}
+}
+
+// securityEventConfig 返回填充默认值后的配置
+func (o Options) securityEventConfig() *SecurityEventConfig {
+	cfg := o.securityEvent
+	if cfg.Vendor == "" {
+		cfg.Vendor = defaultSecurityVendor
+	}
+	if cfg.Product == "" {
+		cfg.Product = defaultSecurityProduct
+	}
+	if cfg.Version == "" {
+		cfg.Version = defaultSecurityVersion
+	}
+	if cfg.EventKey == "" {
+		cfg.EventKey = defaultSecurityEventKey
+	}
+	if cfg.Severity == nil {
+		cfg.Severity = SecuritySeverity
+	}
+	return &cfg
+}
+
+// securityEvent 拆分出事件 ID，返回其余字段
+func (c *SecurityEventConfig) securityEvent(r *Record) (string, []Field) {
+	for i, f := range r.Fields {
+		if f.Key == c.EventKey {
+			return securityValue(f.Value), append(r.Fields[:i:i], r.Fields[i+1:]...)
+		}
+	}
+	return r.Message, r.Fields
+}
+
+func (c *SecurityEventConfig) fieldName(key string) string {
+	if name, ok := c.FieldMapping[key]; ok {
+		return name
+	}
+	return key
+}
+
+func (c *SecurityEventConfig) severity(level Level) int {
+	return min(max(c.Severity(level), 0), 10)
+}
+
+// appendRecordCEF 编码为 ArcSight Common Event Format
+// CEF:0|Vendor|Product|Version|Signature ID|Name|Severity|Extension
+// 日志内容写入 Name 和 msg，时间写入 rt（毫秒时间戳），日志名称写入 cat
+func appendRecordCEF(buf *bytes.Buffer, r *Record, cfg *SecurityEventConfig) {
+	event, fields := cfg.securityEvent(r)
+	buf.WriteString("CEF:0|")
+	for _, s := range []string{cfg.Vendor, cfg.Product, cfg.Version, event, r.Message} {
+		writeSecurityHeader(buf, s)
+		buf.WriteByte('|')
+	}
+	buf.WriteString(strconv.Itoa(cfg.severity(r.Level)))
+	buf.WriteString("|rt=" + strconv.FormatInt(r.Time.UnixMilli(), 10))
+	buf.WriteString(" msg=")
+	writeCEFValue(buf, r.Message)
+	if r.Logger != "" {
+		buf.WriteString(" cat=")
+		writeCEFValue(buf, r.Logger)
+	}
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		writeSecurityKey(buf, cfg.fieldName(f.Key))
+		buf.WriteByte('=')
+		writeCEFValue(buf, securityValue(f.Value))
+	}
+}
+
+// appendRecordLEEF 编码为 IBM QRadar Log Event Extended Format 1.0
+// LEEF:1.0|Vendor|Product|Version|EventID|，属性以制表符分隔，严重程度写入 sev，时间写入 devTime
+func appendRecordLEEF(buf *bytes.Buffer, r *Record, location *time.Location, cfg *SecurityEventConfig) {
+	event, fields := cfg.securityEvent(r)
+	buf.WriteString("LEEF:1.0|")
+	for _, s := range []string{cfg.Vendor, cfg.Product, cfg.Version, event} {
+		writeSecurityHeader(buf, s)
+		buf.WriteByte('|')
+	}
+	buf.WriteString("devTime=")
+	writeLEEFValue(buf, r.Time.In(location).Format(leefTimeFormat))
+	buf.WriteString("\tdevTimeFormat=MMM dd yyyy HH:mm:ss.SSS z")
+	buf.WriteString("\tsev=" + strconv.Itoa(cfg.severity(r.Level)))
+	buf.WriteString("\tmsg=")
+	writeLEEFValue(buf, r.Message)
+	if r.Logger != "" {
+		buf.WriteString("\tcat=")
+		writeLEEFValue(buf, r.Logger)
+	}
+	for _, f := range fields {
+		buf.WriteByte('\t')
+		writeSecurityKey(buf, cfg.fieldName(f.Key))
+		buf.WriteByte('=')
+		writeLEEFValue(buf, securityValue(f.Value))
+	}
+}
+
+// securityValue 字段值转换为字符串，复合类型编码为 JSON
+func securityValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case error:
+		return val.Error()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(val)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+// writeSecurityHeader 头部字段转义反斜杠和竖线，换行替换为空格
+func writeSecurityHeader(buf *bytes.Buffer, s string) {
+	for _, c := range s {
+		switch c {
+		case '\\', '|':
+			buf.WriteByte('\\')
+			buf.WriteRune(c)
+		case '\r', '\n':
+			buf.WriteByte(' ')
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
+
+// writeSecurityKey 字段名只保留字母、数字、下划线和点，其他字符替换为下划线
+func writeSecurityKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, c := range key {
+		if c == '_' || c == '.' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+			buf.WriteRune(c)
+		} else {
+			buf.WriteByte('_')
+		}
+	}
+}
+
+// writeCEFValue 扩展字段的值转义反斜杠和等号，换行写为 \n 和 \r
+func writeCEFValue(buf *bytes.Buffer, s string) {
+	for _, c := range s {
+		switch c {
+		case '\\', '=':
+			buf.WriteByte('\\')
+			buf.WriteRune(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
+
+// writeLEEFValue 属性的值转义反斜杠，分隔符制表符和换行写为 \t、\n、\r
+func writeLEEFValue(buf *bytes.Buffer, s string) {
+	for _, c := range s {
+		switch c {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
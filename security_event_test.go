@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecurityEventOutput(t *testing.T) {
+	for _, typ := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(typ), func(t *testing.T) {
+			audit := &syncBuffer{}
+			logger, err := NewLoggerWithType(typ,
+				WithConsole(ConsoleNone),
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithMark(),
+				WithSecurityEvent(SecurityEventConfig{
+					Vendor:       "Acme",
+					Product:      "Billing",
+					Version:      "2.1",
+					FieldMapping: map[string]string{"user": "suser", "ip": "src"},
+				}),
+				WithSecurityEventOutput(FormatCEF, audit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("user login", "event", "login", "user", "alice", "ip", "10.0.0.1", "password", "secret")
+			logger.Info("report generated", "rows", 10)
+			logger.Warn("permission changed", "event", "role_grant", "user", "bob", "role", "admin")
+
+			lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("audit = %q", audit.String())
+			}
+			if !strings.HasPrefix(lines[0], "CEF:0|Acme|Billing|2.1|login|user login|3|rt=") ||
+				!strings.Contains(lines[0], " msg=user login suser=alice src=10.0.0.1 password=") {
+				t.Fatalf("line = %q", lines[0])
+			}
+			if strings.Contains(lines[0], "secret") {
+				t.Fatalf("password not masked: %q", lines[0])
+			}
+			if !strings.HasPrefix(lines[1], "CEF:0|Acme|Billing|2.1|role_grant|permission changed|6|") {
+				t.Fatalf("line = %q", lines[1])
+			}
+		})
+	}
+}
+
+func TestSecurityEventEscaping(t *testing.T) {
+	opts := Options{securityEvent: SecurityEventConfig{Vendor: "A|B", Severity: func(Level) int { return 42 }}}
+	cfg := opts.securityEventConfig()
+	r := &Record{
+		Time:    time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		Level:   ErrorLevel,
+		Message: "bad\\path|x",
+		Fields:  []Field{{Key: "query", Value: "a=b\nc\td"}, {Key: "bad key", Value: map[string]int{"n": 1}}},
+	}
+
+	var buf bytes.Buffer
+	appendRecordCEF(&buf, r, cfg)
+	want := `CEF:0|A\|B|go-logger|1.0|bad\\path\|x|bad\\path\|x|10|rt=1714550400000 msg=bad\\path|x query=a\=b\nc	d bad_key={"n":1}`
+	if buf.String() != want {
+		t.Fatalf("cef = %q\nwant  %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	appendRecordLEEF(&buf, r, time.UTC, cfg)
+	want = "LEEF:1.0|A\\|B|go-logger|1.0|bad\\\\path\\|x|devTime=May 01 2024 08:00:00.000 UTC\tdevTimeFormat=MMM dd yyyy HH:mm:ss.SSS z\tsev=10\tmsg=bad\\\\path|x\tquery=a=b\\nc\\td\tbad_key={\"n\":1}"
+	if buf.String() != want {
+		t.Fatalf("leef = %q\nwant   %q", buf.String(), want)
+	}
+}
@@ -6,55 +6,61 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path"
 	"runtime"
 	"time"
 )
 
 type slogLogger struct {
-	logger          *slog.Logger
-	errorLogger     *slog.Logger
-	maskLogger      *MaskProcessor
-	filePath        string
-	addSource       bool
-	level           Level
-	colorScheme     *ColorScheme
-	replaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
+	logger      *slog.Logger
+	errorLogger *slog.Logger
+	maskLogger  *MaskProcessor
+	multiline   MultilinePolicy
+	addSource   bool
+	level       Level
+	levelVar    *slog.LevelVar // 控制台和文件输出共享，SetLevel 对 WithFields 派生的实例同样生效
+	name        string
+	fields      []any
+	sinks       []Sink
 }
 
 var _ Logger = (*slogLogger)(nil)
 
-var defaultReplaceAttrFunc = func(location *time.Location, format string) func(groups []string, a slog.Attr) slog.Attr {
+var defaultReplaceAttrFunc = func(location *time.Location, format string, keys FieldKeys, levelFormat LevelFormat) func(groups []string, a slog.Attr) slog.Attr {
 	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
 		if a.Key == slog.TimeKey {
 			t := a.Value.Time().In(location) // 转换时区
 			a.Value = slog.StringValue(t.Format(format))
 		}
-		return a
+		if source, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey {
+			// 调用信息使用 file:line，与 Record 的结构一致
+			a.Key = defaultSchema.CallerKey
+			a.Value = slog.StringValue(fmt.Sprintf("%s:%d", path.Base(source.File), source.Line))
+		}
+		return slogReplaceKeys(keys, levelFormat, a)
 	}
 }
 
 func newSlogLogger(opts Options) (Logger, error) {
-	var ioWriters []io.Writer
 	var location *time.Location
 	location, err := time.LoadLocation(opts.TimeZone)
 	if err != nil {
 		return nil, err
 	}
-	// 创建日志属性替换函数，确保日志时间符合时区
-	replaceAttrFunc := defaultReplaceAttrFunc(location, opts.TimeFormat)
-	// 设置日志轮转
-	if opts.LogRotation != nil {
-		logRotation := initLogRotation(opts.LogRotation.FilePath,
-			opts.LogRotation.MaxSize,
-			opts.LogRotation.MaxAge,
-			opts.LogRotation.MaxBackups,
-			opts.LogRotation.Compress)
-		ioWriters = append(ioWriters, logRotation.logger)
+	sinks, err := newSinks(opts)
+	if err != nil {
+		return nil, err
 	}
-
+	// 创建日志属性替换函数，确保日志时间符合时区，并按配置重命名标准字段
+	replaceAttrFunc := defaultReplaceAttrFunc(location, opts.TimeFormat, opts.fieldKeys, opts.levelFormat)
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(ToSlogLoggerLevel(opts.Level))
 	handlerOpts := &slog.HandlerOptions{
 		AddSource:   opts.AddSource,
-		Level:       ToSlogLoggerLevel(opts.Level),
+		Level:       levelVar,
 		ReplaceAttr: replaceAttrFunc,
 	}
 	handlerErrorOpts := &slog.HandlerOptions{
@@ -62,37 +68,145 @@ func newSlogLogger(opts Options) (Logger, error) {
 		Level:       ToSlogLoggerLevel(ErrorLevel),
 		ReplaceAttr: replaceAttrFunc,
 	}
-	// 设置控制台和文件输出
-	ioWriters = append(ioWriters, os.Stdout, getOutput(opts.FilePath))
-	multiWriter := io.MultiWriter(ioWriters...)
-	var handler slog.Handler
-	var errorHandler slog.Handler
-	if opts.JSONFormat {
-		handler = slog.NewJSONHandler(multiWriter, handlerOpts)
-		errorHandler = slog.NewJSONHandler(getOutput(opts.ErrorOutput), handlerErrorOpts)
-	} else {
-		handler = slog.NewTextHandler(multiWriter, handlerOpts)
-		errorHandler = slog.NewTextHandler(getOutput(opts.ErrorOutput), handlerErrorOpts)
+	// 每个输出使用各自的格式和颜色，slog 不支持的格式由 formatOutputs 输出
+	var handlers slogMultiHandler
+	formats := newFormatOutputs(opts, isTextOrJSON)
+	// 设置日志轮转
+	if opts.LogRotation != nil {
+		logRotation := initLogRotation(opts.LogRotation.FilePath,
+			opts.LogRotation.MaxSize,
+			opts.LogRotation.MaxAge,
+			opts.LogRotation.MaxBackups,
+			opts.LogRotation.Compress)
+		if !formats.addTarget(OutputRotation, nil, logRotation.logger) {
+			handlers = append(handlers, newSlogOutputHandler(opts, OutputRotation, logRotation.logger, handlerOpts))
+		}
+	}
+	// 设置文件输出
+	if opts.FilePath != "" && !formats.addTargetFile(OutputFile, nil, opts.FilePath) {
+		handlers = append(handlers, newSlogOutputHandler(opts, OutputFile, getOutput(opts.FilePath), handlerOpts))
+	}
+	// 设置控制台输出
+	if console := consoleOutput(opts.Console, os.Stdout); console != nil {
+		if !formats.addTarget(OutputConsole, nil, console) {
+			handlers = append(handlers, newSlogOutputHandler(opts, OutputConsole, console, handlerOpts))
+		}
+	}
+	// 额外的输出与 Format 一致
+	if len(opts.outputs) > 0 {
+		outputs := io.MultiWriter(opts.outputs...)
+		if !formats.add(opts.Format, nil, outputs) {
+			handlers = append(handlers, newSlogHandler(outputs, opts.Format == FormatJSON, handlerOpts))
+		}
+	}
+	var errorLogger *slog.Logger
+	if opts.ErrorOutput != "" && !formats.addTargetFile(OutputErrorFile, MatchMinLevel(ErrorLevel), opts.ErrorOutput) {
+		errorLogger = slog.New(newSlogOutputHandler(opts, OutputErrorFile, getOutput(opts.ErrorOutput), handlerErrorOpts))
+	}
+	if formats.err != nil {
+		closeSinks(append(sinks, formats.sinks...))
+		return nil, formats.err
 	}
 
-	logger := &slogLogger{
-		filePath:        opts.FilePath,
-		addSource:       opts.AddSource,
-		logger:          slog.New(handler),
-		errorLogger:     slog.New(errorHandler),
-		level:           opts.Level,
-		replaceAttrFunc: replaceAttrFunc,
+	mainLogger := slog.New(handlers)
+	if opts.Name != "" {
+		mainLogger = mainLogger.With(defaultSchema.LoggerKey, opts.Name)
+		if errorLogger != nil {
+			errorLogger = errorLogger.With(defaultSchema.LoggerKey, opts.Name)
+		}
 	}
-	// 设置颜色输出
-	if opts.ColorEnabled {
-		logger.colorScheme = opts.ColorScheme
+	logger := &slogLogger{
+		addSource:   opts.AddSource,
+		logger:      mainLogger,
+		errorLogger: errorLogger,
+		level:       opts.Level,
+		levelVar:    levelVar,
+		name:        opts.Name,
+		sinks:       append(sinks, formats.sinks...),
 	}
 	if opts.MaskEnable {
-		logger.maskLogger = NewMaskProcessor(opts.maskRules...)
+		logger.maskLogger = newMaskProcessor(opts)
 	}
+	logger.multiline = opts.multiline
 	return logger, nil
 }
 
+// newSlogOutputHandler 按输出的格式和颜色配置创建 Handler
+func newSlogOutputHandler(opts Options, target OutputTarget, w io.Writer, handlerOpts *slog.HandlerOptions) slog.Handler {
+	handler := newSlogHandler(w, opts.outputJSON(target), handlerOpts)
+	if scheme := opts.outputColor(target); scheme != nil {
+		return &slogColorHandler{Handler: handler, colorScheme: scheme}
+	}
+	return handler
+}
+
+func newSlogHandler(w io.Writer, json bool, opts *slog.HandlerOptions) slog.Handler {
+	if json {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// slogMultiHandler 将日志记录分发到多个 Handler，用于控制台和文件使用不同格式
+type slogMultiHandler []slog.Handler
+
+func (h slogMultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h slogMultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, handler := range h {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h slogMultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(slogMultiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h slogMultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(slogMultiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
+
+// slogColorHandler 为日志内容着色
+type slogColorHandler struct {
+	slog.Handler
+	colorScheme *ColorScheme
+}
+
+func (h *slogColorHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Message = h.colorScheme.Colorize(FromSlogLevel(r.Level), r.Message)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *slogColorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &slogColorHandler{Handler: h.Handler.WithAttrs(attrs), colorScheme: h.colorScheme}
+}
+
+func (h *slogColorHandler) WithGroup(name string) slog.Handler {
+	return &slogColorHandler{Handler: h.Handler.WithGroup(name), colorScheme: h.colorScheme}
+}
+
 // ToSlogLoggerLevel 将自定义的 Level 转换为 slog.Level
 func ToSlogLoggerLevel(level Level) slog.Level {
 	switch level {
@@ -125,24 +239,43 @@ func FromSlogLevel(slogLevel slog.Level) Level {
 	}
 }
 
+// log 按 MultilinePolicy 处理日志内容后写入，拆分后的每条日志单独写入
+// write 比 log 多一层调用栈，调用信息的层数以 write 为准
 func (l *slogLogger) log(level slog.Level, msg string, args ...any) {
-	if !l.logger.Enabled(context.Background(), level) {
+	if l.multiline == MultilineKeep {
+		l.write(level, msg, args...)
+		return
+	}
+	for _, part := range applyMultiline(l.multiline, msg, args) {
+		l.write(level, part.msg, part.args...)
+	}
+}
+
+func (l *slogLogger) write(level slog.Level, msg string, args ...any) {
+	// 没有任何输出时仍然需要写入 Sink，这里按级别判断
+	enabled := level >= l.levelVar.Level()
+	sinkEnabled := sinksEnabled(l.sinks, FromSlogLevel(level), enabled)
+	if !enabled && !sinkEnabled {
 		return
 	}
 
 	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:]) // 跳过 3 层调用栈
-	if l.colorScheme != nil {
-		msg = l.colorScheme.Colorize(FromSlogLevel(level), msg)
+	runtime.Callers(4, pcs[:]) // 跳过 4 层调用栈
+	if l.maskLogger != nil {
+		args = l.maskLogger.Process(args...)
+	}
+	if sinkEnabled {
+		writeSinks(l.sinks, enabled, newRecord(l.name, FromSlogLevel(level), msg, l.addSource, 4, l.fields, args))
+	}
+	if !enabled {
+		return
 	}
 	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
 
 	if len(args) > 0 {
 		if len(args)%2 != 0 {
-			args = append(args, "!MISSING!")
-		}
-		if l.maskLogger != nil {
-			args = l.maskLogger.Process(args...)
+			// 限制容量，避免 append 写入调用方的底层数组
+			args = append(args[:len(args):len(args)], "!MISSING!")
 		}
 		for i := 0; i < len(args); i += 2 {
 			if key, ok := args[i].(string); ok {
@@ -201,31 +334,30 @@ func (l *slogLogger) Fatalf(format string, args ...any) {
 }
 
 func (l *slogLogger) SetLevel(level Level) {
-	// 重新创建 Logger 实例以应用新的日志级别
-	handlerOpts := &slog.HandlerOptions{
-		AddSource:   l.addSource,
-		Level:       ToSlogLoggerLevel(level),
-		ReplaceAttr: l.replaceAttrFunc,
-	}
-	newHandler := slog.NewTextHandler(getOutput(l.filePath), handlerOpts)
-	l.logger = slog.New(newHandler)
+	l.levelVar.Set(ToSlogLoggerLevel(level))
 	l.level = level
 }
 
 func (l *slogLogger) WithFields(fields map[string]any) Logger {
-	attrs := make([]slog.Attr, 0, len(fields))
-	for k, v := range fields {
-		attrs = append(attrs, slog.Attr{Key: k, Value: slog.AnyValue(v)})
+	// 持久化字段与单次调用参数走同一条脱敏链路
+	if l.maskLogger != nil {
+		fields = l.maskLogger.ProcessFields(fields)
 	}
-	//  解决 []slog.Attr 转换为 []any 的问题
-	args := make([]any, len(attrs))
-	for i, attr := range attrs {
-		args[i] = attr
+	fields = multilineFields(l.multiline, fields)
+	args := fieldsToArgs(fields)
+	newLogger := &slogLogger{
+		logger:     l.logger.With(args...),
+		maskLogger: l.maskLogger,
+		multiline:  l.multiline,
+		level:      l.level,
+		levelVar:   l.levelVar,
+		addSource:  l.addSource,
+		name:       l.name,
+		fields:     append(l.fields[:len(l.fields):len(l.fields)], args...),
+		sinks:      l.sinks,
 	}
-	return &slogLogger{
-		logger:          l.logger.With(args...),
-		level:           l.level,
-		addSource:       l.addSource,
-		replaceAttrFunc: l.replaceAttrFunc,
+	if l.errorLogger != nil {
+		newLogger.errorLogger = l.errorLogger.With(args...)
 	}
+	return newLogger
 }
@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFacility syslog 设施值
+type SyslogFacility int
+
+// syslog 设施定义 / Syslog facilities (RFC 5424 Section 6.2.1)
+const (
+	FacilityKern     SyslogFacility = iota // 内核消息 / kernel messages
+	FacilityUser                           // 用户级消息 / user-level messages
+	FacilityMail                           // 邮件系统 / mail system
+	FacilityDaemon                         // 系统守护进程 / system daemons
+	FacilityAuth                           // 安全认证 / security/authorization messages
+	FacilitySyslog                         // syslogd 内部消息 / messages generated internally by syslogd
+	FacilityLPR                            // 打印子系统 / line printer subsystem
+	FacilityNews                           // 网络新闻 / network news subsystem
+	FacilityUUCP                           // UUCP 子系统 / UUCP subsystem
+	FacilityCron                           // 定时任务 / clock daemon
+	FacilityAuthPriv                       // 私有安全认证 / security/authorization messages
+	FacilityFTP                            // FTP 守护进程 / FTP daemon
+	_
+	_
+	_
+	_
+	FacilityLocal0 // 本地使用 0 / local use 0
+	FacilityLocal1 // 本地使用 1 / local use 1
+	FacilityLocal2 // 本地使用 2 / local use 2
+	FacilityLocal3 // 本地使用 3 / local use 3
+	FacilityLocal4 // 本地使用 4 / local use 4
+	FacilityLocal5 // 本地使用 5 / local use 5
+	FacilityLocal6 // 本地使用 6 / local use 6
+	FacilityLocal7 // 本地使用 7 / local use 7
+)
+
+// SyslogFormat syslog 消息格式
+type SyslogFormat int
+
+const (
+	// RFC5424 新版 syslog 格式，支持结构化数据
+	RFC5424 SyslogFormat = iota
+	// RFC3164 传统 BSD syslog 格式，字段以 key=value 追加到消息末尾
+	RFC3164
+)
+
+// 默认结构化数据 ID，32473 为 RFC 5612 中保留给文档示例的企业号
+const defaultSyslogStructuredDataID = "fields@32473"
+
+// 本地 syslog 套接字路径
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig syslog 输出配置
+type SyslogConfig struct {
+	// Network 网络类型：udp、tcp、unix、unixgram，为空时连接本地 syslog 套接字
+	Network string
+	// Addr 地址，unix 套接字为文件路径
+	Addr string
+	// Facility 设施值
+	Facility SyslogFacility
+	// AppName 应用名称，为空时使用进程名
+	AppName string
+	// Hostname 主机名，为空时使用 os.Hostname
+	Hostname string
+	// Format 消息格式，默认 RFC5424
+	Format SyslogFormat
+	// StructuredDataID RFC5424 结构化数据 ID，默认 fields@32473
+	StructuredDataID string
+}
+
+// SyslogSink syslog 日志输出端
+type SyslogSink struct {
+	cfg     SyslogConfig
+	pid     string
+	mu      sync.Mutex
+	conn    net.Conn
+	framing bool // 流式连接使用 RFC 6587 octet-counting 分帧
+}
+
+var _ Sink = (*SyslogSink)(nil)
+
+// NewSyslogSink 创建 syslog 输出端并建立连接，默认使用 RFC 5424 格式
+// Network 支持 udp、tcp、unix、unixgram，Network 和 Addr 都为空时连接本地 syslog 套接字
+// 日志级别映射：Debug->debug，Info->informational，Warn->warning，Error->error，Fatal->critical
+// 通过 WithSink 添加到日志实例，输出端由调用方持有，退出前需要调用 Close 关闭连接，例如：
+//
+//	sink, err := logger.NewSyslogSink(logger.SyslogConfig{Network: "udp", Addr: "localhost:514", Facility: logger.FacilityLocal0})
+//	log, err := logger.NewLogger(logger.WithSink(sink))
+//	defer sink.Close()
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	if cfg.AppName == "" {
+		cfg.AppName = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.StructuredDataID == "" {
+		cfg.StructuredDataID = defaultSyslogStructuredDataID
+	}
+	s := &SyslogSink{
+		cfg: cfg,
+		pid: strconv.Itoa(os.Getpid()),
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ToSyslogSeverity 将 Level 转换为 syslog 严重性
+func ToSyslogSeverity(level Level) int {
+	switch level {
+	case DebugLevel:
+		return 7 // debug
+	case InfoLevel:
+		return 6 // informational
+	case WarnLevel:
+		return 4 // warning
+	case ErrorLevel:
+		return 3 // error
+	case FatalLevel:
+		return 2 // critical
+	default:
+		return 6
+	}
+}
+
+func (s *SyslogSink) connect() error {
+	if s.cfg.Network == "" && s.cfg.Addr == "" {
+		return s.connectLocal()
+	}
+	conn, err := net.Dial(s.cfg.Network, s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.framing = s.cfg.Network == "tcp" || s.cfg.Network == "tcp4" || s.cfg.Network == "tcp6" || s.cfg.Network == "unix"
+	return nil
+}
+
+func (s *SyslogSink) connectLocal() error {
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSyslogPaths {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				s.conn = conn
+				s.framing = false // 本地 syslog 守护进程按行读取
+				return nil
+			}
+		}
+	}
+	return errors.New("unix syslog delivery error")
+}
+
+// WriteRecord 写入一条日志记录，写入失败时重连一次
+func (s *SyslogSink) WriteRecord(r *Record) error {
+	msg := s.format(r)
+	if s.framing {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	} else if s.cfg.Network == "" {
+		msg = append(msg, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		if _, err := s.conn.Write(msg); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.connect(); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// Close 关闭连接
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *SyslogSink) format(r *Record) []byte {
+	pri := int(s.cfg.Facility)*8 + ToSyslogSeverity(r.Level)
+	if s.cfg.Format == RFC3164 {
+		return s.formatRFC3164(pri, r)
+	}
+	return s.formatRFC5424(pri, r)
+}
+
+// formatRFC5424 <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID key="value"...] MSG
+func (s *SyslogSink) formatRFC5424(pri int, r *Record) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s - ", pri,
+		r.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(s.cfg.Hostname, 255),
+		syslogHeaderField(s.cfg.AppName, 48),
+		syslogHeaderField(s.pid, 128))
+
+	caller := r.Caller()
+	if len(r.Fields) == 0 && caller == "" {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteByte('[')
+		buf.WriteString(s.cfg.StructuredDataID)
+		if caller != "" {
+			writeSyslogParam(&buf, defaultSchema.CallerKey, caller)
+		}
+		for _, f := range r.Fields {
+			writeSyslogParam(&buf, f.Key, fmt.Sprint(f.Value))
+		}
+		buf.WriteByte(']')
+	}
+	if r.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(r.Message)
+	}
+	return buf.Bytes()
+}
+
+// formatRFC3164 <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value...
+func (s *SyslogSink) formatRFC3164(pri int, r *Record) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>%s %s %s[%s]: %s", pri,
+		r.Time.Format(time.Stamp),
+		syslogHeaderField(s.cfg.Hostname, 255),
+		syslogHeaderField(s.cfg.AppName, 32),
+		s.pid,
+		r.Message)
+	if caller := r.Caller(); caller != "" {
+		buf.WriteString(" " + defaultSchema.CallerKey + "=")
+		buf.WriteString(caller)
+	}
+	for _, f := range r.Fields {
+		fmt.Fprintf(&buf, " %s=%s", f.Key, strconv.Quote(fmt.Sprint(f.Value)))
+	}
+	return buf.Bytes()
+}
+
+// syslogHeaderField 头部字段只允许可打印 ASCII 字符，为空时使用 NILVALUE
+func syslogHeaderField(value string, maxLen int) string {
+	var b strings.Builder
+	for _, c := range value {
+		if c > 32 && c < 127 {
+			b.WriteRune(c)
+		}
+		if b.Len() == maxLen {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// writeSyslogParam 写入结构化数据参数
+// PARAM-NAME 不允许 '='、空格、']'、'"'，最长 32 个字符；PARAM-VALUE 需要转义 '"'、'\' 和 ']'
+func writeSyslogParam(buf *bytes.Buffer, name, value string) {
+	var n strings.Builder
+	for _, c := range name {
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		n.WriteRune(c)
+		if n.Len() == 32 {
+			break
+		}
+	}
+	if n.Len() == 0 {
+		return
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(n.String())
+	buf.WriteString(`="`)
+	for _, c := range value {
+		if c == '"' || c == '\\' || c == ']' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(c)
+	}
+	buf.WriteByte('"')
+}
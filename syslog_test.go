@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogOutputUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: "udp", Addr: conn.LocalAddr().String(), Facility: FacilityLocal0, AppName: "myapp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	for _, loggerType := range []LoggerType{SlogLogger, ZapLogger, LogrusLogger, KlogLogger} {
+		t.Run(string(loggerType), func(t *testing.T) {
+			logger, err := NewLoggerWithType(loggerType,
+				WithFileOutput(filepath.Join(t.TempDir(), "app.log")),
+				WithSink(sink),
+				WithMark())
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.WithFields(map[string]any{"request_id": "r-1"}).Warn("disk almost full", "password", "secret", "usage", 95)
+
+			buf := make([]byte, 2048)
+			_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := string(buf[:n])
+			// local0(16)*8 + warning(4)
+			if !strings.HasPrefix(msg, "<132>1 ") {
+				t.Fatalf("unexpected header: %s", msg)
+			}
+			if !strings.Contains(msg, " myapp ") {
+				t.Fatalf("app name missing: %s", msg)
+			}
+			want := `[fields@32473 request_id="r-1" password="[****\]" usage="95"] disk almost full`
+			if !strings.HasSuffix(msg, want) {
+				t.Fatalf("message = %s\nwant suffix %s", msg, want)
+			}
+		})
+	}
+}
+
+func TestSyslogOutputTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			// RFC 6587 octet-counting: MSG-LEN SP SYSLOG-MSG
+			size, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: "tcp", Addr: ln.Addr().String(), Facility: FacilityUser, AppName: "myapp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger, err := NewLoggerWithType(SlogLogger, WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Error("multi\nline")
+	logger.Info("no fields")
+
+	for _, want := range []string{"<11>1 ", "<14>1 "} {
+		select {
+		case msg := <-received:
+			if !strings.HasPrefix(msg, want) {
+				t.Fatalf("message = %q, want prefix %q", msg, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for syslog message")
+		}
+	}
+}
+
+func TestSyslogOutputUnixRFC3164(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "syslog.sock")
+	conn, err := net.ListenPacket("unixgram", socket)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: "unixgram", Addr: socket, Facility: FacilityDaemon, AppName: "myapp", Format: RFC3164})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	logger, err := NewLoggerWithType(LogrusLogger, WithFileOutput(filepath.Join(t.TempDir(), "app.log")), WithAddSource(), WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("started", "port", 8080)
+
+	buf := make([]byte, 2048)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<30>") {
+		t.Fatalf("unexpected priority: %s", msg)
+	}
+	if !strings.Contains(msg, " myapp[") || !strings.Contains(msg, `]: started caller=syslog_test.go:`) {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+	if !strings.HasSuffix(msg, ` port="8080"`) {
+		t.Fatalf("fields missing: %s", msg)
+	}
+}
+
+func TestToSyslogSeverity(t *testing.T) {
+	want := map[Level]int{DebugLevel: 7, InfoLevel: 6, WarnLevel: 4, ErrorLevel: 3, FatalLevel: 2}
+	for level, severity := range want {
+		if got := ToSyslogSeverity(level); got != severity {
+			t.Errorf("ToSyslogSeverity(%d) = %d, want %d", level, got, severity)
+		}
+	}
+}
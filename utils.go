@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"sort"
 )
 
 func getCaller(callNum int) string {
@@ -31,9 +32,28 @@ func getOutput(filePath string) io.Writer {
 	if filePath == "" {
 		return io.Discard
 	}
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
+	file, err := openOutput(filePath)
 	if err != nil {
 		return io.Discard
 	}
 	return file
 }
+
+// openOutput 以追加方式打开日志文件，不存在时创建
+func openOutput(filePath string) (*os.File, error) {
+	return os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
+}
+
+// fieldsToArgs 将字段集合转换为按 key 排序的 KV 参数列表
+func fieldsToArgs(fields map[string]any) []any {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]any, 0, len(fields)*2)
+	for _, k := range keys {
+		args = append(args, k, fields[k])
+	}
+	return args
+}
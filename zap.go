@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -14,8 +15,12 @@ type zapLogger struct {
 	logger      *zap.SugaredLogger
 	errorLogger *zap.SugaredLogger
 	maskLogger  *MaskProcessor
+	multiline   MultilinePolicy
 	level       Level
-	colorScheme *ColorScheme
+	addSource   bool
+	name        string
+	fields      []any
+	sinks       []Sink
 }
 
 var _ Logger = (*zapLogger)(nil)
@@ -25,13 +30,17 @@ func newZapLogger(opts Options) (Logger, error) {
 	if err != nil {
 		return nil, err
 	}
+	sinks, err := newSinks(opts)
+	if err != nil {
+		return nil, err
+	}
 	// 构建通用的 zap.Config
-	buildConfig := func(level zap.AtomicLevel) zap.Config {
+	buildConfig := func(level zap.AtomicLevel, json bool) zap.Config {
 		cfg := zap.NewProductionConfig()
 		cfg.Level = level
 		cfg.DisableCaller = true
 		cfg.DisableStacktrace = true // 关闭 error 级别的堆栈打印（zap 默认会打印）
-		if !opts.JSONFormat {
+		if !json {
 			cfg.Encoding = "console"
 		}
 		// 设置时区
@@ -40,51 +49,155 @@ func newZapLogger(opts Options) (Logger, error) {
 			enc.AppendString(t.Format(opts.TimeFormat))
 		}
 		cfg.EncoderConfig.EncodeTime = timeEncoder
+		// 与 Record 的结构一致：time、level（大写）、logger、msg
+		cfg.EncoderConfig.TimeKey = defaultSchema.TimeKey
+		cfg.EncoderConfig.NameKey = defaultSchema.LoggerKey
+		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		// 标准字段的 key 和级别写法
+		applyZapKeys(&cfg.EncoderConfig, opts.fieldKeys, opts.levelFormat)
 		return cfg
 	}
 
-	// 创建主日志配置
-	mainCfg := buildConfig(ToZapLevel(opts.Level))
-	if opts.FilePath != "" {
-		mainCfg.OutputPaths = []string{"stderr", opts.FilePath}
-	}
-	// 创建 error 日志配置
-	errorCfg := buildConfig(ToZapLevel(ErrorLevel))
-	if opts.FilePath != "" {
+	// zap 不支持的格式由 formatOutputs 输出
+	formats := newFormatOutputs(opts, isTextOrJSON)
+	// 创建主日志配置，只输出到文件，其余输出单独创建
+	mainCfg := buildConfig(ToZapLevel(opts.Level), opts.outputJSON(OutputFile))
+	mainCfg.OutputPaths = nil
+	if opts.FilePath != "" && !formats.addTargetFile(OutputFile, nil, opts.FilePath) {
 		mainCfg.OutputPaths = []string{opts.FilePath}
 	}
+	// 创建 error 日志配置，未设置 ErrorOutput 时不输出，避免与控制台重复
+	errorCfg := buildConfig(ToZapLevel(ErrorLevel), opts.outputJSON(OutputErrorFile))
+	errorCfg.OutputPaths = nil
+	errorNative := true
 	if opts.ErrorOutput != "" {
-		errorCfg.OutputPaths = []string{opts.ErrorOutput}
+		errorNative = !formats.addTargetFile(OutputErrorFile, MatchMinLevel(ErrorLevel), opts.ErrorOutput)
+		if errorNative {
+			errorCfg.OutputPaths = []string{opts.ErrorOutput}
+		}
 	}
 	errorCfg.DisableStacktrace = false
 	// if opts.TimeFormat != "" {
 	// 	mainCfg.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(opts.TimeFormat)
 	// 	errorCfg.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(opts.TimeFormat)
 	// }
-	logger, err := mainCfg.Build()
+	var cores []zapcore.Core
+	// 额外的日志输出与 Format 一致
+	if len(opts.outputs) > 0 {
+		outputs := io.MultiWriter(opts.outputs...)
+		if !formats.add(opts.Format, nil, outputs) {
+			encoder := newZapEncoder(mainCfg.EncoderConfig, opts.Format == FormatJSON)
+			cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(outputs), mainCfg.Level))
+		}
+	}
+	// 设置日志轮转
+	if opts.LogRotation != nil {
+		logRotation := initLogRotation(opts.LogRotation.FilePath,
+			opts.LogRotation.MaxSize,
+			opts.LogRotation.MaxAge,
+			opts.LogRotation.MaxBackups,
+			opts.LogRotation.Compress)
+		if !formats.addTarget(OutputRotation, nil, logRotation.logger) {
+			cores = append(cores, newZapOutputCore(opts, OutputRotation, mainCfg, zapcore.AddSync(logRotation.logger)))
+		}
+	}
+	// 控制台输出，默认在未设置文件输出时输出到 stderr
+	var defaultConsole io.Writer
+	if opts.FilePath == "" {
+		defaultConsole = os.Stderr
+	}
+	if console := consoleOutput(opts.Console, defaultConsole); console != nil {
+		if !formats.addTarget(OutputConsole, nil, console) {
+			cores = append(cores, newZapOutputCore(opts, OutputConsole, mainCfg, zapcore.Lock(zapcore.AddSync(console))))
+		}
+	}
+	if formats.err != nil {
+		closeSinks(append(sinks, formats.sinks...))
+		return nil, formats.err
+	}
+	fileScheme := opts.outputColor(OutputFile)
+	logger, err := mainCfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if fileScheme != nil {
+			core = &zapColorCore{Core: core, colorScheme: fileScheme}
+		}
+		return zapcore.NewTee(append([]zapcore.Core{core}, cores...)...)
+	}))
 	if err != nil {
 		return nil, err
 	}
-	errorLogger, err := errorCfg.Build()
+	var errorBuildOpts []zap.Option
+	if scheme := opts.outputColor(OutputErrorFile); scheme != nil {
+		errorBuildOpts = append(errorBuildOpts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &zapColorCore{Core: core, colorScheme: scheme}
+		}))
+	}
+	errorLogger, err := errorCfg.Build(errorBuildOpts...)
 	if err != nil {
 		return nil, err
 	}
 	zapLogger := &zapLogger{
-		logger:      logger.Sugar(),
-		errorLogger: errorLogger.Sugar(),
-		level:       opts.Level,
+		logger:    logger.Sugar(),
+		level:     opts.Level,
+		addSource: opts.AddSource,
+		name:      opts.Name,
+		sinks:     append(sinks, formats.sinks...),
 	}
-	// 设置颜色输出
-	if opts.ColorEnabled {
-		zapLogger.colorScheme = opts.ColorScheme
+	if errorNative {
+		zapLogger.errorLogger = errorLogger.Sugar()
+	}
+	if opts.Name != "" {
+		zapLogger.logger = zapLogger.logger.Named(opts.Name)
+		if zapLogger.errorLogger != nil {
+			zapLogger.errorLogger = zapLogger.errorLogger.Named(opts.Name)
+		}
 	}
 	// 设置日志脱敏
 	if opts.MaskEnable {
-		zapLogger.maskLogger = NewMaskProcessor(opts.maskRules...)
+		zapLogger.maskLogger = newMaskProcessor(opts)
 	}
+	zapLogger.multiline = opts.multiline
 	return zapLogger, nil
 }
 
+// newZapOutputCore 按输出的格式和颜色配置创建 Core
+func newZapOutputCore(opts Options, target OutputTarget, cfg zap.Config, ws zapcore.WriteSyncer) zapcore.Core {
+	encoder := newZapEncoder(cfg.EncoderConfig, opts.outputJSON(target))
+	var core zapcore.Core = zapcore.NewCore(encoder, ws, cfg.Level)
+	if scheme := opts.outputColor(target); scheme != nil {
+		core = &zapColorCore{Core: core, colorScheme: scheme}
+	}
+	return core
+}
+
+func newZapEncoder(cfg zapcore.EncoderConfig, json bool) zapcore.Encoder {
+	if json {
+		return zapcore.NewJSONEncoder(cfg)
+	}
+	return zapcore.NewConsoleEncoder(cfg)
+}
+
+// zapColorCore 为日志内容着色
+type zapColorCore struct {
+	zapcore.Core
+	colorScheme *ColorScheme
+}
+
+func (c *zapColorCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapColorCore{Core: c.Core.With(fields), colorScheme: c.colorScheme}
+}
+
+func (c *zapColorCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *zapColorCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.colorScheme.Colorize(FromZapLevel(ent.Level), ent.Message)
+	return c.Core.Write(ent, fields)
+}
+
 func ToZapLevel(level Level) zap.AtomicLevel {
 	switch level {
 	case DebugLevel:
@@ -119,15 +232,28 @@ func FromZapLevel(zapLevel zapcore.Level) Level {
 	}
 }
 
+// log 按 MultilinePolicy 处理日志内容后写入，拆分后的每条日志单独写入
 func (l *zapLogger) log(level zapcore.Level, msg string, args ...any) {
-	if l.colorScheme != nil {
-		msg = l.colorScheme.Colorize(FromZapLevel(level), msg)
+	if l.multiline == MultilineKeep {
+		l.write(level, msg, args...)
+		return
+	}
+	for _, part := range applyMultiline(l.multiline, msg, args) {
+		l.write(level, part.msg, part.args...)
 	}
-	caller := getCaller(3)
-	msg = "source=" + caller + " " + msg // 直接拼接字符串，减少 fmt.Sprintf
+}
+
+func (l *zapLogger) write(level zapcore.Level, msg string, args ...any) {
 	if l.maskLogger != nil {
 		args = l.maskLogger.Process(args...)
 	}
+	if enabled := FromZapLevel(level) >= l.level; sinksEnabled(l.sinks, FromZapLevel(level), enabled) {
+		writeSinks(l.sinks, enabled, newRecord(l.name, FromZapLevel(level), msg, l.addSource, 4, l.fields, args))
+	}
+	if l.addSource {
+		// 调用信息写入 caller 字段，与 Record 的结构一致；限制容量，避免 append 写入调用方的底层数组
+		args = append(args[:len(args):len(args)], defaultSchema.CallerKey, getCaller(4))
+	}
 	switch level {
 	case zap.DebugLevel:
 		l.logger.Debugw(msg, args...)
@@ -198,8 +324,24 @@ func (l *zapLogger) Fatalf(format string, args ...any) {
 
 func (l *zapLogger) SetLevel(level Level) { /* Zap Logger 的 Level 不能动态修改 */ }
 func (l *zapLogger) WithFields(fields map[string]any) Logger {
-	return &zapLogger{
-		logger: l.logger.With(fields),
-		level:  l.level,
+	// 持久化字段与单次调用参数走同一条脱敏链路
+	if l.maskLogger != nil {
+		fields = l.maskLogger.ProcessFields(fields)
+	}
+	fields = multilineFields(l.multiline, fields)
+	args := fieldsToArgs(fields)
+	newLogger := &zapLogger{
+		logger:     l.logger.With(args...),
+		maskLogger: l.maskLogger,
+		multiline:  l.multiline,
+		level:      l.level,
+		addSource:  l.addSource,
+		name:       l.name,
+		fields:     append(l.fields[:len(l.fields):len(l.fields)], args...),
+		sinks:      l.sinks,
+	}
+	if l.errorLogger != nil {
+		newLogger.errorLogger = l.errorLogger.With(args...)
 	}
+	return newLogger
 }